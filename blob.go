@@ -1,7 +1,10 @@
 package oath
 
+import "context"
+
 type Blob interface {
 	Verify(value string) error
+	VerifyContext(ctx context.Context, value string) error
 	Synchronized() bool
-	OTPURI(account, issuer string) string
+	OTPURI(account, issuer string) (string, error)
 }
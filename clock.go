@@ -0,0 +1,9 @@
+package oath
+
+import "time"
+
+// Clock provides the current time. It is used wherever the current time is required,
+// like while verifying TOTP values or checking whether a blob is locked.
+type Clock interface {
+	Now() time.Time
+}
@@ -7,13 +7,78 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/dadrus/oath/otp"
 )
 
-var ErrInvalidBlob = errors.New("invalid blob")
+var (
+	ErrInvalidBlob = errors.New("invalid blob")
+	// ErrDecryptionFailed is returned if the blob cannot be decrypted, e.g. because it
+	// has been sealed with another key, or has been modified.
+	ErrDecryptionFailed = errors.New("blob decryption failed")
+	// ErrSubjectMismatch is returned if the blob is bound to another subject. It matches
+	// ErrDecryptionFailed.
+	ErrSubjectMismatch = fmt.Errorf("%w: blob is not bound to the given subject", ErrDecryptionFailed)
+	// ErrNonceGeneration is returned if the blob cannot be sealed, because reading the
+	// nonce from the source of randomness failed.
+	ErrNonceGeneration = errors.New("nonce generation failed")
+)
+
+const (
+	// outOfWindowSkew defines how many steps beyond the validity window are checked
+	// on failed verifications to tell apart wrong values from values outside the window
+	outOfWindowSkew = 10
+
+	envelopeVersion = "v1"
+	// boundEnvelopeVersion is used for blobs bound to a subject
+	boundEnvelopeVersion = "v2"
+)
+
+// settings holds the configuration, which is not part of the sealed blob
+type settings struct {
+	subject      string
+	clock        Clock
+	resyncWindow int
+	maxRetries   *int
+	masterSecret []byte
+	keyPolicy    *otp.KeyPolicy
+	keyLength    int
+	random       io.Reader
+	keyProvider  KeyProvider
+	// lockout holds the lockout policy given while opening a blob. It is merged into the
+	// policy stored in the blob.
+	lockout *lockout
+
+	unboundBlobMigration bool
+}
+
+func (s settings) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+
+	return s.clock.Now()
+}
+
+// randomBytes reads length bytes from the configured source of randomness, which defaults
+// to crypto/rand.
+func (s settings) randomBytes(length int) ([]byte, error) {
+	random := s.random
+	if random == nil {
+		random = rand.Reader
+	}
+
+	buf := make([]byte, length)
+
+	if _, err := io.ReadFull(random, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
 
 type config struct {
 	Key           []byte            `json:"key"`
@@ -27,7 +92,31 @@ type config struct {
 	Synchronized  bool              `json:"synchronized,omitempty"`
 	WorkSkew      int               `json:"skew,omitempty"`
 	InitialSkew   int               `json:"initial_skew,omitempty"`
-	LastVerified  []string          `json:"last_verified,omitempty"`
+	// Drift is the tracked clock drift of a TOTP client. Earlier versions stored it in
+	// seconds in Deviation, which is migrated on the next successful verification.
+	Drift time.Duration `json:"drift,omitempty"`
+	// WorkFutureSkew and InitialFutureSkew define the look-ahead of TOTP validity windows.
+	// If not set, WorkSkew and InitialSkew are used for both directions.
+	WorkFutureSkew    *int `json:"future_skew,omitempty"`
+	InitialFutureSkew *int `json:"initial_future_skew,omitempty"`
+	// NextStep is the smallest TOTP time step, which can still be accepted
+	NextStep int64 `json:"next_step,omitempty"`
+	// LastVerified holds the otp values verified by earlier versions. It is only read
+	// to migrate these to NextStep.
+	LastVerified []string `json:"last_verified,omitempty"`
+
+	Lockout        *lockout `json:"lockout,omitempty"`
+	FailedAttempts int      `json:"failed_attempts,omitempty"`
+	// LockedUntil is the unix time in milliseconds, until which the blob is locked
+	LockedUntil int64 `json:"locked_until,omitempty"`
+
+	// KeyDerivation holds the inputs to derive Key from the master secret. If set, Key
+	// is not stored in the blob.
+	KeyDerivation *keyDerivation `json:"kdf,omitempty"`
+	// KeyReference references the key kept by a key provider. If set, Key is empty.
+	KeyReference string `json:"key_ref,omitempty"`
+
+	settings settings
 }
 
 func (b *config) Skew() int {
@@ -38,37 +127,208 @@ func (b *config) Skew() int {
 	return b.InitialSkew
 }
 
+// Window returns the look-behind and the look-ahead of the current TOTP validity window
+func (b *config) Window() (int, int) {
+	if b.Synchronized {
+		return b.WorkSkew, b.workFutureSkew()
+	}
+
+	return b.InitialSkew, b.initialFutureSkew()
+}
+
+func (b *config) workFutureSkew() int {
+	if b.WorkFutureSkew != nil {
+		return *b.WorkFutureSkew
+	}
+
+	return b.WorkSkew
+}
+
+func (b *config) initialFutureSkew() int {
+	if b.InitialFutureSkew != nil {
+		return *b.InitialFutureSkew
+	}
+
+	return b.InitialSkew
+}
+
 func (b *config) unmarshal(value string, c cipher.AEAD) error {
+	const (
+		legacyParts    = 3
+		versionedParts = 5
+	)
+
+	ring := keyring(c)
 	parts := strings.Split(value, "$")
-	if len(parts) != 3 {
+
+	switch {
+	case len(parts) == legacyParts && len(parts[0]) == 0:
+		if err := b.checkUnbound(); err != nil {
+			return err
+		}
+
+		// unversioned blob without key id. All known keys are tried to open it.
+		return b.open(ring, parts[1], parts[2], nil)
+	case len(parts) == versionedParts && len(parts[0]) == 0:
+		return b.openVersioned(ring, parts[1], parts[2], parts[3], parts[4])
+	default:
+		return ErrInvalidBlob
+	}
+}
+
+func (b *config) openVersioned(ring *Keyring, version, encodedKID, encodedNonce, encodedData string) error {
+	if version != envelopeVersion && version != boundEnvelopeVersion {
 		return ErrInvalidBlob
 	}
 
-	nonce, _ := base64.RawStdEncoding.DecodeString(parts[1])
-	encrypted, _ := base64.RawStdEncoding.DecodeString(parts[2])
+	kid, err := base64.RawStdEncoding.DecodeString(encodedKID)
+	if err != nil {
+		return ErrInvalidBlob
+	}
+
+	key, err := ring.key(string(kid))
+	if err != nil {
+		return err
+	}
+
+	if version == envelopeVersion {
+		if err = b.checkUnbound(); err != nil {
+			return err
+		}
+
+		return b.open(key, encodedNonce, encodedData, envelopeHeader(version, encodedKID, ""))
+	}
+
+	if len(b.settings.subject) == 0 {
+		return ErrSubjectMismatch
+	}
+
+	unsealed, err := decrypt(key, encodedNonce, encodedData, envelopeHeader(version, encodedKID, b.settings.subject))
+	if err != nil {
+		if errors.Is(err, ErrInvalidBlob) {
+			return err
+		}
+
+		// the blob is either bound to another subject or has been modified
+		return ErrSubjectMismatch
+	}
+
+	defer otp.Wipe(unsealed)
+
+	return json.Unmarshal(unsealed, b)
+}
+
+// checkUnbound returns ErrSubjectMismatch if a subject is given for a blob, which is not
+// bound to any subject, unless their migration has been enabled. Migrated blobs are bound
+// when sealed the next time.
+func (b *config) checkUnbound() error {
+	if len(b.settings.subject) != 0 && !b.settings.unboundBlobMigration {
+		return ErrSubjectMismatch
+	}
+
+	return nil
+}
 
-	unsealed, err := c.Open([]byte{}, nonce, encrypted, nil)
+func (b *config) open(c cipher.AEAD, encodedNonce, encodedData string, header []byte) error {
+	unsealed, err := decrypt(c, encodedNonce, encodedData, header)
 	if err != nil {
 		return err
 	}
 
+	defer otp.Wipe(unsealed)
+
 	return json.Unmarshal(unsealed, b)
 }
 
+// wipe overwrites the key material held by the config with zeros. The config must not
+// be used afterwards.
+func (b *config) wipe() {
+	otp.Wipe(b.Key)
+	otp.Wipe(b.settings.masterSecret)
+}
+
+func (b config) String() string {
+	return fmt.Sprintf("%s(%s, %d digits)", b.Type, b.HashAlgorithm, b.Digits)
+}
+
+func (b config) GoString() string {
+	return fmt.Sprintf("oath.config{Type: %q, Key: %s, HashAlgorithm: %q, Digits: %d, Counter: %d}",
+		b.Type, otp.Redacted, b.HashAlgorithm, b.Digits, b.Counter)
+}
+
+func (b config) Format(f fmt.State, verb rune) { otp.FormatRedacted(f, verb, b) }
+
+// marshal seals the config with the current key of the keyring (or the given cipher, if it
+// is not a keyring). The resulting blob has the following format
+//
+//	$<version>$<key id>$<nonce>$<ciphertext>
+//
+// with all parts being base64 encoded. The version and the key id are authenticated as well.
+// If a subject is configured, the version is v2 and the subject is authenticated in addition,
+// so that the blob can only be opened for that subject. Otherwise, the version is v1.
 func (b *config) marshal(c cipher.AEAD) (string, error) {
-	res, err := json.Marshal(b)
+	stored := *b
+	if stored.KeyDerivation != nil {
+		stored.Key = nil
+	}
+
+	res, err := json.Marshal(&stored)
 	if err != nil {
 		return "", err
 	}
 
-	nonce := make([]byte, c.NonceSize())
-	rand.Read(nonce)
+	defer otp.Wipe(res)
+
+	ring := keyring(c)
+	key, _ := ring.Key(ring.CurrentKeyID())
+	kid := base64.RawStdEncoding.EncodeToString([]byte(ring.CurrentKeyID()))
+
+	nonce, err := b.settings.randomBytes(key.NonceSize())
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrNonceGeneration, err)
+	}
+
+	version := envelopeVersion
+	if len(b.settings.subject) != 0 {
+		version = boundEnvelopeVersion
+	}
 
-	sealed := c.Seal([]byte{}, nonce, res, nil)
+	sealed := key.Seal([]byte{}, nonce, res, envelopeHeader(version, kid, b.settings.subject))
 
 	return fmt.Sprintf(
-		"$%s$%s",
+		"$%s$%s$%s$%s",
+		version,
+		kid,
 		base64.RawStdEncoding.EncodeToString(nonce),
 		base64.RawStdEncoding.EncodeToString(sealed),
-	), err
+	), nil
+}
+
+func decrypt(c cipher.AEAD, encodedNonce, encodedData string, header []byte) ([]byte, error) {
+	nonce, err := base64.RawStdEncoding.DecodeString(encodedNonce)
+	if err != nil || len(nonce) != c.NonceSize() {
+		return nil, ErrInvalidBlob
+	}
+
+	encrypted, err := base64.RawStdEncoding.DecodeString(encodedData)
+	if err != nil {
+		return nil, ErrInvalidBlob
+	}
+
+	unsealed, err := c.Open([]byte{}, nonce, encrypted, header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecryptionFailed, err)
+	}
+
+	return unsealed, nil
+}
+
+// envelopeHeader returns the additional data used while sealing and opening the blob
+func envelopeHeader(version, kid, subject string) []byte {
+	header := fmt.Sprintf("$%s$%s", version, kid)
+	if len(subject) != 0 {
+		header += "$" + subject
+	}
+
+	return []byte(header)
 }
@@ -0,0 +1,297 @@
+package oath
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/hotp"
+	"github.com/dadrus/oath/otp"
+	"github.com/dadrus/oath/totp"
+)
+
+func newAEAD(t *testing.T) cipher.AEAD {
+	t.Helper()
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	return aead
+}
+
+func newHOTP(t *testing.T, key []byte, opts ...hotp.Option) *hotp.Algorithm {
+	t.Helper()
+
+	alg, err := hotp.New(key, opts...)
+	require.NoError(t, err)
+
+	return alg
+}
+
+func newTOTP(t *testing.T, key []byte, opts ...totp.Option) *totp.Algorithm {
+	t.Helper()
+
+	alg, err := totp.New(key, opts...)
+	require.NoError(t, err)
+
+	return alg
+}
+
+func TestConfigMarshalUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	c := newAEAD(t)
+	cfg := config{Key: []byte{1, 2, 3}, Type: "totp", Period: 30 * time.Second}
+
+	// WHEN
+	sealed, err := cfg.marshal(c)
+	require.NoError(t, err)
+
+	var res config
+	err = res.unmarshal(sealed, c)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, cfg, res)
+	assert.True(t, strings.HasPrefix(sealed, "$v1$$"))
+}
+
+func TestConfigUnmarshalLegacyFormat(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	c := newAEAD(t)
+	cfg := config{Key: []byte{1, 2, 3}, Type: "hotp", Counter: 10}
+
+	raw, err := json.Marshal(cfg)
+	require.NoError(t, err)
+
+	nonce := make([]byte, c.NonceSize())
+	sealed := fmt.Sprintf("$%s$%s",
+		base64.RawStdEncoding.EncodeToString(nonce),
+		base64.RawStdEncoding.EncodeToString(c.Seal(nil, nonce, raw, nil)))
+
+	ring := NewKeyring("new", newAEAD(t), WithRetiredKey("old", c))
+
+	// WHEN
+	var res config
+	err = res.unmarshal(sealed, ring)
+
+	strict := config{settings: settings{subject: "alice"}}
+	strictErr := strict.unmarshal(sealed, ring)
+
+	migrated := config{settings: settings{subject: "alice", unboundBlobMigration: true}}
+	migrateErr := migrated.unmarshal(sealed, ring)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, cfg, res)
+
+	// legacy blobs are not bound to any subject
+	require.ErrorIs(t, strictErr, ErrSubjectMismatch)
+	require.NoError(t, migrateErr)
+	assert.Equal(t, cfg.Key, migrated.Key)
+}
+
+func TestConfigUnmarshalFails(t *testing.T) {
+	t.Parallel()
+
+	c := newAEAD(t)
+	cfg := config{Key: []byte{1, 2, 3}, Type: "hotp"}
+
+	sealed, err := cfg.marshal(NewKeyring("foo", c))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc    string
+		value string
+		ring  *Keyring
+		err   error
+	}{
+		{uc: "malformed blob", value: "foo", ring: NewKeyring("foo", c), err: ErrInvalidBlob},
+		{uc: "unsupported version", value: strings.Replace(sealed, "$v1$", "$v9$", 1), ring: NewKeyring("foo", c), err: ErrInvalidBlob},
+		{uc: "unknown key id", value: sealed, ring: NewKeyring("bar", c), err: ErrUnknownKey},
+		{uc: "tampered key id", value: strings.Replace(sealed, "$Zm9v$", "$YmFy$", 1), ring: NewKeyring("foo", c, WithRetiredKey("bar", c))},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			var res config
+			err := res.unmarshal(tc.value, tc.ring)
+
+			// THEN
+			require.Error(t, err)
+
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestVerifyMigratesBlobsSealedWithPlainCipher(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	oldKey := newAEAD(t)
+	newKey := newAEAD(t)
+
+	blob, err := TOTP.New(oldKey, WithKey(key))
+	require.NoError(t, err)
+
+	ring := NewKeyring("2023-06", newKey, WithRetiredKey("2022-12", oldKey))
+	value := newTOTP(t, key).Generate(time.Now().Unix())
+
+	// WHEN
+	updated, synced, err := Verify(value, blob, ring)
+
+	// THEN
+	require.NoError(t, err)
+	assert.True(t, synced)
+	assert.True(t, strings.HasPrefix(blob, "$v1$$"))
+	assert.True(t, strings.HasPrefix(updated, "$v1$MjAyMy0wNg$"))
+
+	// a blob with empty key id is not opened by an unrelated keyring
+	var res config
+	err = res.unmarshal(blob, NewKeyring("foo", newAEAD(t)))
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestVerifyResealsWithCurrentKey(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	oldKey := newAEAD(t)
+	newKey := newAEAD(t)
+
+	blob, err := TOTP.New(NewKeyring("old", oldKey), WithKey(key))
+	require.NoError(t, err)
+
+	ring := NewKeyring("new", newKey, WithRetiredKey("old", oldKey))
+	value := newTOTP(t, key).Generate(time.Now().Unix())
+
+	// WHEN
+	updated, synced, err := Verify(value, blob, ring)
+
+	// THEN
+	require.NoError(t, err)
+	assert.True(t, synced)
+	assert.True(t, strings.HasPrefix(updated, "$v1$bmV3$"))
+
+	// the updated blob can be opened without the retired key
+	_, _, err = Export(updated, NewKeyring("new", newKey), "foo", "bar")
+	require.NoError(t, err)
+}
+
+func TestConfigSubjectBinding(t *testing.T) {
+	t.Parallel()
+
+	c := newAEAD(t)
+
+	bound, err := (&config{Type: "hotp", settings: settings{subject: "alice"}}).marshal(c)
+	require.NoError(t, err)
+
+	unbound, err := (&config{Type: "hotp"}).marshal(c)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc      string
+		value   string
+		subject string
+		migrate bool
+		err     error
+	}{
+		{uc: "bound blob opened for its subject", value: bound, subject: "alice"},
+		{uc: "bound blob opened for another subject", value: bound, subject: "bob", err: ErrSubjectMismatch},
+		{uc: "bound blob opened without subject", value: bound, err: ErrSubjectMismatch},
+		{uc: "unbound blob opened with subject", value: unbound, subject: "bob", err: ErrSubjectMismatch},
+		{uc: "unbound blob opened with subject while migrating", value: unbound, subject: "bob", migrate: true},
+		{uc: "unbound blob opened without subject", value: unbound},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			res := config{settings: settings{subject: tc.subject, unboundBlobMigration: tc.migrate}}
+			err := res.unmarshal(tc.value, c)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "hotp", res.Type)
+			}
+		})
+	}
+}
+
+func TestVerifyBindsBlobToSubject(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+
+	blob, err := TOTP.New(c, WithKey(key))
+	require.NoError(t, err)
+
+	value := newTOTP(t, key).Generate(time.Now().Unix())
+
+	// WHEN
+	_, _, strictErr := Verify(value, blob, c, WithSubject("alice"))
+	updated, _, err := Verify(value, blob, c, WithSubject("alice"), WithUnboundBlobMigration())
+
+	// THEN
+	require.ErrorIs(t, strictErr, ErrSubjectMismatch)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(updated, "$v2$"))
+
+	_, _, err = Export(updated, c, "alice", "foo", WithSubject("alice"))
+	require.NoError(t, err)
+
+	_, _, err = Export(updated, c, "bob", "foo", WithSubject("bob"))
+	require.ErrorIs(t, err, ErrSubjectMismatch)
+}
+
+func TestConfigUnmarshalWithWrongKey(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	sealed, err := (&config{Type: "hotp"}).marshal(newAEAD(t))
+	require.NoError(t, err)
+
+	// WHEN
+	var res config
+	err = res.unmarshal(sealed, newAEAD(t))
+
+	// THEN
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+	require.NotErrorIs(t, err, ErrSubjectMismatch)
+}
+
+func TestConfigFormat(t *testing.T) {
+	t.Parallel()
+
+	data := config{Type: "totp", Key: []byte("12345678901234567890"), HashAlgorithm: otp.SHA1, Digits: 6}
+
+	assert.Equal(t, "totp(SHA1, 6 digits)", fmt.Sprintf("%x", &data))
+	assert.Equal(t, `oath.config{Type: "totp", Key: [REDACTED], HashAlgorithm: "SHA1", Digits: 6, Counter: 0}`,
+		fmt.Sprintf("%#v", data))
+}
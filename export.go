@@ -2,19 +2,18 @@ package oath
 
 import (
 	"crypto/cipher"
-	"encoding/base32"
-	"strings"
 )
 
 // Export exports the data from the blob in the OTPAUTH format (first return value),
-// as well as the key base32 encoded (second return value)
-func Export(blobValue string, c cipher.AEAD, account, issuer string) (string, string, error) {
-	data, blb, err := blob(blobValue, c)
+// as well as the key base32 encoded (second return value). As with Verify, only options
+// not affecting the blob configuration itself are taken into account.
+func Export(blobValue string, c cipher.AEAD, account, issuer string, opts ...Option) (string, string, error) {
+	tok, err := Open(blobValue, c, opts...)
 	if err != nil {
 		return "", "", err
 	}
 
-	encoded := base32.StdEncoding.EncodeToString(data.Key)
+	defer tok.Destroy()
 
-	return blb.OTPURI(account, issuer), strings.TrimRight(encoded, "="), nil
+	return tok.Export(account, issuer)
 }
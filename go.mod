@@ -3,7 +3,6 @@ module github.com/dadrus/oath
 go 1.20
 
 require (
-	github.com/stephennancekivell/go-future v0.0.0-20220519100038-8611b539078e
 	github.com/stretchr/testify v1.8.3
 	golang.org/x/exp v0.0.0-20230519143937-03e91628a987
 )
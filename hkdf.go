@@ -0,0 +1,39 @@
+package oath
+
+import (
+	"crypto/hmac"
+	"hash"
+
+	"github.com/dadrus/oath/otp"
+)
+
+// hkdf implements the HMAC-based extract-and-expand key derivation function as defined in
+// RFC 5869. length must not exceed 255 times the size of the hash.
+func hkdf(hash func() hash.Hash, secret, salt, info []byte, length int) []byte {
+	if len(salt) == 0 {
+		salt = make([]byte, hash().Size())
+	}
+
+	extractor := hmac.New(hash, salt)
+	extractor.Write(secret)
+	prk := extractor.Sum(nil)
+
+	defer otp.Wipe(prk)
+
+	expander := hmac.New(hash, prk)
+	out := make([]byte, 0, length+expander.Size())
+
+	var block []byte
+
+	for counter := byte(1); len(out) < length; counter++ {
+		expander.Reset()
+		expander.Write(block)
+		expander.Write(info)
+		expander.Write([]byte{counter})
+		block = expander.Sum(block[:0])
+
+		out = append(out, block...)
+	}
+
+	return out[:length]
+}
@@ -1,7 +1,8 @@
 package oath
 
 import (
-	"golang.org/x/exp/slices"
+	"context"
+	"errors"
 
 	"github.com/dadrus/oath/hotp"
 	"github.com/dadrus/oath/otp"
@@ -14,38 +15,103 @@ type hotpBlob struct {
 
 func (b *hotpBlob) Synchronized() bool { return b.c.Synchronized }
 
-func (b *hotpBlob) OTPURI(account, issuer string) string {
-	return otpauth.ToURI(b.algorithm(), account, otpauth.WithIssuer(issuer))
+func (b *hotpBlob) OTPURI(account, issuer string) (string, error) {
+	alg, err := b.algorithm()
+	if err != nil {
+		return "", err
+	}
+
+	defer alg.Destroy()
+
+	if alg.Key() == nil {
+		return "", ErrKeyNotExportable
+	}
+
+	return otpauth.ToURI(alg, account, otpauth.WithIssuer(issuer)), nil
 }
 
 func (b *hotpBlob) Verify(value string) error {
-	alg := b.algorithm()
-	skew := b.c.Skew()
+	return b.VerifyContext(context.Background(), value)
+}
 
-	deviation, err := alg.Validate(value, b.c.Counter, hotp.WithSkew(skew))
+func (b *hotpBlob) VerifyContext(ctx context.Context, value string) error {
+	alg, err := b.algorithm()
 	if err != nil {
 		return err
 	}
 
-	if !slices.Contains(b.c.LastVerified, value) {
-		b.c.Synchronized = true
-		b.c.Deviation = deviation
-		b.c.Counter = b.c.Counter + deviation + 1
+	defer alg.Destroy()
 
-		if len(b.c.LastVerified) >= skew {
-			b.c.LastVerified = b.c.LastVerified[1:]
-		}
+	// the validity window starts at the counter following the last accepted one.
+	// So there is no way to accept an otp value twice.
+	res, err := alg.ValidateContext(ctx, value, b.c.Counter, hotp.WithSkew(b.c.Skew()))
+	if err != nil {
+		return b.classify(alg, value, err)
+	}
 
-		b.c.LastVerified = append(b.c.LastVerified, value)
-	} else {
-		return otp.ErrValidation
+	b.c.Synchronized = true
+	b.c.Deviation = res.Deviation
+	b.c.Counter = res.Step + 1
+	// the last verified values stored by earlier versions are not required anymore
+	b.c.LastVerified = nil
+
+	return nil
+}
+
+func (b *hotpBlob) Resync(ctx context.Context, values []string, window int) error {
+	alg, err := b.algorithm()
+	if err != nil {
+		return err
 	}
 
+	defer alg.Destroy()
+
+	res, err := alg.ResyncContext(ctx, values, b.c.Counter, hotp.WithSkew(window-1))
+	if err != nil {
+		return err
+	}
+
+	b.c.Synchronized = true
+	b.c.Deviation = res.Deviation
+	b.c.Counter = res.Step + 1
+	b.c.LastVerified = nil
+
 	return nil
 }
 
-func (b *hotpBlob) algorithm() *hotp.Algorithm {
-	return hotp.New(b.c.Key,
+// classify checks whether the given value, which failed the validation, has been
+// used already, or is ahead of the validity window.
+func (b *hotpBlob) classify(alg *hotp.Algorithm, value string, err error) error {
+	if !errors.Is(err, otp.ErrValidation) {
+		return err
+	}
+
+	if b.c.Counter > 0 {
+		start := b.c.Counter - outOfWindowSkew
+		if start < 0 {
+			start = 0
+		}
+
+		if _, vErr := alg.Validate(value, start, hotp.WithSkew(int(b.c.Counter-start-1))); vErr == nil {
+			return otp.ErrReplayed
+		}
+	}
+
+	skew := int64(b.c.Skew())
+	if _, vErr := alg.Validate(value, b.c.Counter+skew+1, hotp.WithSkew(outOfWindowSkew-1)); vErr == nil {
+		return otp.ErrOutOfWindow
+	}
+
+	return err
+}
+
+func (b *hotpBlob) algorithm() (*hotp.Algorithm, error) {
+	handle, err := b.c.keyHandle()
+	if err != nil {
+		return nil, err
+	}
+
+	return hotp.NewFromHandle(handle,
 		hotp.WithHashAlgorithm(b.c.HashAlgorithm),
 		hotp.WithDigits(b.c.Digits),
 	)
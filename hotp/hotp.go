@@ -3,75 +3,167 @@ package hotp
 
 import (
 	"bytes"
-	"crypto/hmac"
+	"context"
 	"crypto/subtle"
-	"encoding/binary"
-	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/stephennancekivell/go-future/future"
-	"github.com/stephennancekivell/go-future/tuple"
-
 	"github.com/dadrus/oath/otp"
 )
 
 type Algorithm struct {
-	key       []byte
+	key       otp.KeyHandle
 	digits    otp.Digits
 	algorithm otp.HashAlgorithm
+	policy    otp.KeyPolicy
+	macs      *macPool
+}
+
+// New creates a new HOTP algorithm using the given key held in memory. Returns
+// otp.ErrUnsupportedHashAlgorithm if the configured hash algorithm has not been registered,
+// and otp.ErrWeakKey if the key does not fulfill the key policy (otp.DefaultKeyPolicy,
+// if not configured otherwise).
+func New(key []byte, opts ...Option) (*Algorithm, error) {
+	alg, err := newAlgorithm(otp.MemoryKey(bytes.Clone(key)), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = alg.policy.Check(key); err != nil {
+		return nil, err
+	}
+
+	return alg, nil
 }
 
-func New(key []byte, opts ...Option) *Algorithm {
+// NewFromHandle creates a new HOTP algorithm, which lets the given key handle calculate
+// the HMAC, e.g. to keep the key in an HSM. The key policy is not applied. Returns
+// otp.ErrUnsupportedHashAlgorithm if the configured hash algorithm has not been registered.
+func NewFromHandle(handle otp.KeyHandle, opts ...Option) (*Algorithm, error) {
+	return newAlgorithm(handle, opts)
+}
+
+func newAlgorithm(handle otp.KeyHandle, opts []Option) (*Algorithm, error) {
 	const defaultOTPLength = 6
 
 	alg := &Algorithm{
-		key:       bytes.Clone(key),
+		key:       handle,
 		digits:    otp.Digits(defaultOTPLength),
 		algorithm: otp.SHA1,
+		policy:    otp.DefaultKeyPolicy,
 	}
 
 	for _, opt := range opts {
 		opt(alg)
 	}
 
-	return alg
+	if !alg.algorithm.Supported() {
+		return nil, fmt.Errorf("%w: %s", otp.ErrUnsupportedHashAlgorithm, alg.algorithm)
+	}
+
+	alg.macs = newMACPool(alg.algorithm, alg.key)
+
+	return alg, nil
 }
 
-func (a *Algorithm) Key() []byte { return bytes.Clone(a.key) }
+// Key returns the key, if the key handle is able to reveal it (see otp.ExportableKey).
+// Returns nil otherwise.
+func (a *Algorithm) Key() []byte {
+	if key, ok := a.key.(otp.ExportableKey); ok {
+		return key.Key()
+	}
+
+	return nil
+}
+
+// KeyHandle returns the handle used to calculate the HMAC
+func (a *Algorithm) KeyHandle() otp.KeyHandle { return a.key }
 
 func (a *Algorithm) Digits() otp.Digits { return a.digits }
 
 func (a *Algorithm) HashAlgorithm() otp.HashAlgorithm { return a.algorithm }
 
+// KeyPolicy returns the policy the key has been checked against
+func (a *Algorithm) KeyPolicy() otp.KeyPolicy { return a.policy }
+
+// Generate generates the otp value for the given counter. Returns an empty string if
+// the key handle fails to calculate the HMAC. Use AppendGenerate to get the error.
 func (a *Algorithm) Generate(reference int64) string {
-	return Truncate(a.digits, a.calculate(reference))
+	var buf [16]byte
+
+	res, _ := a.AppendGenerate(buf[:0], reference)
+
+	return string(res)
 }
 
-func (a *Algorithm) Validate(value string, reference int64, opts ...otp.ValidationOption) (int64, error) {
-	// The validation is done for the entire validity window defined by skew
-	// Since the validation of each value in the validity window is independent,
-	// these validations are done in parallel.
-	var group []future.Future[tuple.T2[int64, error]]
+// AppendGenerate works like Generate, but appends the otp value to dst and returns
+// the extended buffer. It does not allocate if dst has enough capacity. Returns dst
+// unchanged and otp.ErrMACFailed if the key handle fails to calculate the HMAC.
+func (a *Algorithm) AppendGenerate(dst []byte, reference int64) ([]byte, error) {
+	state := a.macs.get()
+	defer a.macs.put(state)
 
-	for it := a.iterator(opts, reference); it.HasNext(); {
-		group = append(group, a.validateFuture(value, reference, it.Value()))
+	sum, err := state.calculate(reference)
+	if err != nil {
+		return dst, err
 	}
 
-	return a.futureGroupResult(group)
+	return AppendTruncate(dst, a.digits, sum), nil
 }
 
-func (a *Algorithm) calculate(reference int64) []byte {
-	const intSize = 8
+func (a *Algorithm) Validate(value string, reference int64, opts ...otp.ValidationOption) (otp.Result, error) {
+	return a.ValidateContext(context.Background(), value, reference, opts...)
+}
 
-	mac := hmac.New(a.algorithm.Hash, a.key)
+// ValidateContext works like Validate, but aborts the validation with otp.ErrCanceled
+// if the given context is done.
+func (a *Algorithm) ValidateContext(
+	ctx context.Context, value string, reference int64, opts ...otp.ValidationOption,
+) (otp.Result, error) {
+	code := strings.TrimSpace(value)
 
-	buf := make([]byte, intSize)
-	binary.BigEndian.PutUint64(buf, uint64(reference))
+	if err := a.checkFormat(code); err != nil {
+		return otp.Result{}, err
+	}
 
-	mac.Write(buf)
+	var (
+		window  = otp.Window{First: reference, Last: reference}
+		first   = true
+		matched int
+		step    int64
+		buf     [16]byte
+		codeBuf = []byte(code)
+	)
 
-	return mac.Sum(nil)
+	// All counters of the validity window are checked, even if the value has already
+	// been matched. This way the execution time does not depend on the position of the match.
+	for it := a.iterator(opts, reference); it.HasNext(); {
+		if err := canceled(ctx); err != nil {
+			return otp.Result{}, err
+		}
+
+		counter := it.Value()
+		if first {
+			window.First, first = counter, false
+		}
+
+		window.Last = counter
+
+		generated, err := a.AppendGenerate(buf[:0], counter)
+		if err != nil {
+			return otp.Result{}, err
+		}
+
+		match := subtle.ConstantTimeCompare(codeBuf, generated)
+		step = selectStep(match, counter, step)
+		matched |= match
+	}
+
+	if matched == 0 {
+		return otp.Result{}, otp.ErrValidation
+	}
+
+	return otp.Result{Step: step, Deviation: step - reference, Window: window}, nil
 }
 
 func (a *Algorithm) iterator(opts []otp.ValidationOption, reference int64) otp.SkewIterator {
@@ -82,61 +174,38 @@ func (a *Algorithm) iterator(opts []otp.ValidationOption, reference int64) otp.S
 	return opts[0](reference)
 }
 
-func (a *Algorithm) validateFuture(value string, steps int64, tbv int64) future.Future[tuple.T2[int64, error]] {
-	return future.New(func() tuple.T2[int64, error] {
-		if err := a.validate(value, tbv); err != nil {
-			return tuple.New2[int64, error](0, err)
-		}
-
-		return tuple.New2[int64, error](tbv-steps, nil)
-	})
-}
-
-func (a *Algorithm) validate(value string, reference int64) error {
-	code := strings.TrimSpace(value)
-
+func (a *Algorithm) checkFormat(code string) error {
 	if len(code) != a.digits.Length() {
 		return fmt.Errorf("%w: %d", otp.ErrInvalidLength, len(code))
 	}
 
-	calculated := a.Generate(reference)
-
-	if subtle.ConstantTimeCompare([]byte(code), []byte(calculated)) == 0 {
-		return otp.ErrValidation
+	for _, char := range code {
+		if char < '0' || char > '9' {
+			return fmt.Errorf("%w: %q", otp.ErrInvalidCharacter, char)
+		}
 	}
 
 	return nil
 }
 
-func (a *Algorithm) futureGroupResult(group []future.Future[tuple.T2[int64, error]]) (int64, error) {
-	// Constant execution time
-	var (
-		deviation *int64
-		err       error
-	)
-
-	for _, result := range future.Sequence(group).Get() {
-		if rDeviation, rErr := result.Values(); rErr != nil {
-			if !errors.Is(rErr, otp.ErrValidation) {
-				err = rErr
-			}
-		} else {
-			deviation = &rDeviation
-		}
+func canceled(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", otp.ErrCanceled, err)
 	}
 
-	if err != nil {
-		return 0, err
-	} else if deviation != nil {
-		return *deviation, nil
-	}
+	return nil
+}
+
+// selectStep returns candidate if match is 1 and current if match is 0 in constant time
+func selectStep(match int, candidate, current int64) int64 {
+	mask := -int64(match)
 
-	return 0, otp.ErrValidation
+	return (candidate & mask) | (current &^ mask)
 }
 
 func (a *Algorithm) Export(exporter otp.Exporter) {
 	exporter.SetAlgorithm("hotp")
 	exporter.SetDigits(a.digits)
-	exporter.SetKey(a.key)
+	exporter.SetKey(a.Key())
 	exporter.SetHashAlgorithm(a.algorithm)
 }
@@ -1,8 +1,11 @@
 package hotp
 
 import (
+	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,19 +28,73 @@ func TestNew(t *testing.T) {
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEN
-			key := []byte{1, 2, 3}
+			key := []byte("12345678901234567890")
 
 			// WHEN
-			alg := New(key, tc.opts...)
+			alg, err := New(key, tc.opts...)
+			require.NoError(t, err)
 
 			// THEN
 			assert.Equal(t, tc.expDigits, alg.digits)
-			assert.Equal(t, key, alg.key)
+			assert.Equal(t, key, alg.Key())
 			assert.NotNil(t, alg.algorithm)
 		})
 	}
 }
 
+func TestNewWithUnsupportedHashAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	alg, err := New([]byte{1, 2, 3}, WithHashAlgorithm("MD5"))
+
+	// THEN
+	require.ErrorIs(t, err, otp.ErrUnsupportedHashAlgorithm)
+	assert.Nil(t, alg)
+}
+
+func TestNewWithKeyPolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc   string
+		key  []byte
+		opts []Option
+		err  error
+	}{
+		{uc: "empty key", err: otp.ErrKeyTooShort},
+		{uc: "empty key with lax policy", opts: []Option{WithKeyPolicy(otp.LaxKeyPolicy)}, err: otp.ErrKeyTooShort},
+		{uc: "80 bit key", key: []byte("1234567890"), err: otp.ErrKeyTooShort},
+		{uc: "80 bit key with lax policy", key: []byte("1234567890"), opts: []Option{WithKeyPolicy(otp.LaxKeyPolicy)}},
+		{
+			uc:   "128 bit key with recommended policy",
+			key:  []byte("1234567890123456"),
+			opts: []Option{WithKeyPolicy(otp.RecommendedKeyPolicy)},
+			err:  otp.ErrKeyTooShort,
+		},
+		{uc: "all zero key", key: make([]byte, 20), err: otp.ErrLowEntropyKey},
+		{uc: "valid key", key: []byte("12345678901234567890")},
+	} {
+		tc := tc
+
+		t.Run(tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			alg, err := New(tc.key, tc.opts...)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				assert.Nil(t, alg)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, alg)
+			}
+		})
+	}
+}
+
 func TestGenerate(t *testing.T) {
 	t.Parallel()
 
@@ -63,7 +120,8 @@ func TestGenerate(t *testing.T) {
 	} {
 		t.Run(fmt.Sprintf("counter %d", tc.counter), func(t *testing.T) {
 			// GIVEN
-			alg := New(secret)
+			alg, err := New(secret)
+			require.NoError(t, err)
 
 			// WHEN
 			value := alg.Generate(tc.counter)
@@ -74,6 +132,72 @@ func TestGenerate(t *testing.T) {
 	}
 }
 
+// softwareKey is a key handle, which does not benefit from the cached HMAC state of otp.MemoryKey
+type softwareKey struct {
+	key []byte
+	err error
+}
+
+func (k softwareKey) MAC(dst []byte, algorithm otp.HashAlgorithm, message []byte) ([]byte, error) {
+	if k.err != nil {
+		return nil, k.err
+	}
+
+	return otp.MemoryKey(k.key).MAC(dst, algorithm, message)
+}
+
+func TestNewFromHandle(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(t, err)
+
+	alg, err := NewFromHandle(softwareKey{key: secret})
+	require.NoError(t, err)
+
+	// WHEN
+	values, gErr := alg.GenerateRange(0, 3)
+	res, err := alg.Validate("969429", 0, WithSkew(5))
+
+	// THEN
+	require.NoError(t, gErr)
+	assert.Equal(t, []string{"755224", "287082", "359152"}, values)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), res.Step)
+	assert.Nil(t, alg.Key())
+}
+
+func TestNewFromFailingHandle(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	failure := errors.New("hsm unavailable")
+
+	alg, err := NewFromHandle(softwareKey{err: failure})
+	require.NoError(t, err)
+
+	// WHEN
+	value := alg.Generate(0)
+	buf, aErr := alg.AppendGenerate([]byte("foo"), 0)
+	values, gErr := alg.GenerateRange(0, 3)
+	sErr := alg.GenerateStream(0, 3, func(int64, []byte) bool { return true })
+	_, vErr := alg.Validate("755224", 0)
+	_, rErr := alg.Resync([]string{"755224", "287082"}, 0, WithSkew(5))
+
+	// THEN
+	assert.Empty(t, value)
+	require.ErrorIs(t, aErr, otp.ErrMACFailed)
+	assert.Equal(t, "foo", string(buf))
+	require.ErrorIs(t, gErr, otp.ErrMACFailed)
+	require.ErrorIs(t, gErr, failure)
+	assert.Nil(t, values)
+	require.ErrorIs(t, sErr, otp.ErrMACFailed)
+	require.ErrorIs(t, vErr, otp.ErrMACFailed)
+	require.ErrorIs(t, vErr, failure)
+	require.ErrorIs(t, rErr, otp.ErrMACFailed)
+}
+
 func TestValidate(t *testing.T) {
 	t.Parallel()
 
@@ -102,14 +226,17 @@ func TestValidate(t *testing.T) {
 		// the negative cases
 		{counter: 1, otp: " 287082", success: true},
 		{counter: 2, otp: "3591521", success: false, err: otp.ErrInvalidLength},
+		{counter: 2, otp: "35915a", success: false, err: otp.ErrMalformed},
+		{counter: 2, otp: "35915a", success: false, err: otp.ErrValidation},
 		{counter: 2, otp: "520489", success: false},
 	} {
 		t.Run(fmt.Sprintf("counter %d, otp %s", tc.counter, tc.otp), func(t *testing.T) {
 			// GIVEN
-			alg := New(secret)
+			alg, err := New(secret)
+			require.NoError(t, err)
 
 			// WHEN
-			_, err := alg.Validate(tc.otp, tc.counter)
+			_, err = alg.Validate(tc.otp, tc.counter)
 
 			// THEN
 			if tc.success {
@@ -137,24 +264,30 @@ func TestValidateWithSkew(t *testing.T) {
 		skew      int
 		otp       string
 		deviation int64
+		step      int64
 		success   bool
 	}{
-		{uc: "success, no deviation", counter: 1, skew: 1, otp: "287082", deviation: 0, success: true},
-		{uc: "success, 1 deviation", counter: 0, skew: 1, otp: "287082", deviation: 1, success: true},
-		{uc: "success, 2 deviation", counter: 0, skew: 2, otp: "359152", deviation: 2, success: true},
+		{uc: "success, no deviation", counter: 1, skew: 1, otp: "287082", deviation: 0, step: 1, success: true},
+		{uc: "success, 1 deviation", counter: 0, skew: 1, otp: "287082", deviation: 1, step: 1, success: true},
+		{uc: "success, 2 deviation", counter: 0, skew: 2, otp: "359152", deviation: 2, step: 2, success: true},
 		{uc: "fails, deviates too much", counter: 0, skew: 2, otp: "969429", success: false},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVEN
-			alg := New(secret)
+			alg, err := New(secret)
+			require.NoError(t, err)
 
 			// WHEN
-			deviation, err := alg.Validate(tc.otp, tc.counter, WithSkew(tc.skew))
+			res, err := alg.Validate(tc.otp, tc.counter, WithSkew(tc.skew))
 
 			// THEN
 			if tc.success {
 				require.NoError(t, err)
-				assert.Equal(t, tc.deviation, deviation)
+				assert.Equal(t, tc.deviation, res.Deviation)
+				assert.Equal(t, tc.step, res.Step)
+				assert.Equal(t, otp.Window{First: tc.counter, Last: tc.counter + int64(tc.skew)}, res.Window)
+				assert.Zero(t, res.Drift)
+				assert.Zero(t, res.ValidFor)
 			} else {
 				require.ErrorIs(t, err, otp.ErrValidation)
 			}
@@ -169,7 +302,8 @@ func TestAlgorithmExport(t *testing.T) {
 	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
 	require.NoError(t, err)
 
-	alg := New(secret, WithDigits(otp.Digits(10)))
+	alg, err := New(secret, WithDigits(otp.Digits(10)))
+	require.NoError(t, err)
 
 	exporter := mocks.NewExporterMock(t)
 	exporter.EXPECT().SetAlgorithm("hotp")
@@ -180,3 +314,125 @@ func TestAlgorithmExport(t *testing.T) {
 	// WHEN -> expectations are met
 	alg.Export(exporter)
 }
+
+func TestValidateContext(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(t, err)
+
+	alg, err := New(secret)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// WHEN
+	res, err := alg.ValidateContext(ctx, "520489", 0, WithSkew(9))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), res.Step)
+
+	// WHEN
+	cancel()
+	_, err = alg.ValidateContext(ctx, "520489", 0, WithSkew(1000))
+
+	// THEN
+	require.ErrorIs(t, err, otp.ErrCanceled)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// WHEN
+	_, err = alg.ResyncContext(ctx, []string{"399871", "520489"}, 0)
+
+	// THEN
+	require.ErrorIs(t, err, otp.ErrCanceled)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGenerateConcurrently(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(t, err)
+
+	alg, err := New(secret)
+	require.NoError(t, err)
+
+	expected := []string{"755224", "287082", "359152", "969429", "338314"}
+
+	var wg sync.WaitGroup
+
+	// WHEN
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			buf := make([]byte, 0, 6)
+
+			for counter, exp := range expected {
+				// THEN
+				assert.Equal(t, exp, alg.Generate(int64(counter)))
+				res, err := alg.AppendGenerate(buf[:0], int64(counter))
+				assert.NoError(t, err)
+				assert.Equal(t, exp, string(res))
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkValidate(b *testing.B) {
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(b, err)
+
+	alg, err := New(secret)
+	require.NoError(b, err)
+
+	// the execution time must not depend on the position of the match
+	for _, skew := range []int{1, 10, 1000} {
+		for _, position := range []int{0, skew} {
+			value := alg.Generate(int64(position))
+
+			b.Run(fmt.Sprintf("skew %d, match at %d", skew, position), func(b *testing.B) {
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					if _, err := alg.Validate(value, 0, WithSkew(skew)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(b, err)
+
+	alg, err := New(secret)
+	require.NoError(b, err)
+
+	b.Run("Generate", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			_ = alg.Generate(int64(i))
+		}
+	})
+
+	b.Run("AppendGenerate", func(b *testing.B) {
+		b.ReportAllocs()
+
+		buf := make([]byte, 0, alg.Digits().Length())
+
+		for i := 0; i < b.N; i++ {
+			buf, _ = alg.AppendGenerate(buf[:0], int64(i))
+		}
+	})
+}
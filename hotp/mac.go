@@ -0,0 +1,217 @@
+package hotp
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"sync"
+
+	"github.com/dadrus/oath/otp"
+)
+
+const (
+	innerPad = 0x36
+	outerPad = 0x5c
+)
+
+// macState holds the buffers required for the calculation of an HMAC. For in-memory keys,
+// the HMAC is calculated by the state itself. In that case, the state holds the hashes
+// with the inner and outer pads already absorbed (marshaled, if the hash supports it, to
+// save one block per hash and calculation). All buffers holding key material are owned by
+// the state, so that these can be wiped.
+type macState struct {
+	handle    otp.KeyHandle
+	algorithm otp.HashAlgorithm
+
+	inner, outer           hash.Hash
+	innerState, outerState []byte
+	marshaled              bool
+
+	counter  [8]byte
+	innerSum []byte
+	sum      []byte
+}
+
+func newMACState(algorithm otp.HashAlgorithm, handle otp.KeyHandle) *macState {
+	state := &macState{handle: handle, algorithm: algorithm}
+
+	key, ok := handle.(otp.MemoryKey)
+	if !ok {
+		return state
+	}
+
+	state.inner, state.outer = algorithm.Hash(), algorithm.Hash()
+	state.innerState, state.outerState = pads(state.inner, key)
+	state.innerSum = make([]byte, 0, state.inner.Size())
+	state.sum = make([]byte, 0, state.outer.Size())
+
+	state.inner.Write(state.innerState)
+	state.outer.Write(state.outerState)
+
+	innerMarshaler, innerOK := state.inner.(encoding.BinaryMarshaler)
+	outerMarshaler, outerOK := state.outer.(encoding.BinaryMarshaler)
+	_, innerUnmarshalable := state.inner.(encoding.BinaryUnmarshaler)
+	_, outerUnmarshalable := state.outer.(encoding.BinaryUnmarshaler)
+
+	if !innerOK || !outerOK || !innerUnmarshalable || !outerUnmarshalable {
+		return state
+	}
+
+	innerState, innerErr := innerMarshaler.MarshalBinary()
+	outerState, outerErr := outerMarshaler.MarshalBinary()
+
+	if innerErr != nil || outerErr != nil {
+		otp.Wipe(innerState)
+		otp.Wipe(outerState)
+
+		return state
+	}
+
+	otp.Wipe(state.innerState)
+	otp.Wipe(state.outerState)
+
+	state.innerState, state.outerState, state.marshaled = innerState, outerState, true
+
+	return state
+}
+
+// pads returns the inner and outer pads for the given key as defined in RFC 2104
+func pads(h hash.Hash, key []byte) ([]byte, []byte) {
+	blockSize := h.BlockSize()
+	ipad := make([]byte, blockSize)
+	opad := make([]byte, blockSize)
+
+	if len(key) > blockSize {
+		h.Write(key)
+		hashed := h.Sum(nil)
+		h.Reset()
+
+		copy(ipad, hashed)
+		otp.Wipe(hashed)
+	} else {
+		copy(ipad, key)
+	}
+
+	copy(opad, ipad)
+
+	for i := range ipad {
+		ipad[i] ^= innerPad
+		opad[i] ^= outerPad
+	}
+
+	return ipad, opad
+}
+
+func (s *macState) calculate(reference int64) ([]byte, error) {
+	binary.BigEndian.PutUint64(s.counter[:], uint64(reference))
+
+	if s.inner == nil {
+		sum, err := s.handle.MAC(s.sum[:0], s.algorithm, s.counter[:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", otp.ErrMACFailed, err)
+		}
+
+		s.sum = sum
+
+		return s.sum, nil
+	}
+
+	s.reset(s.inner, s.innerState)
+	s.inner.Write(s.counter[:])
+	s.innerSum = s.inner.Sum(s.innerSum[:0])
+
+	s.reset(s.outer, s.outerState)
+	s.outer.Write(s.innerSum)
+	s.sum = s.outer.Sum(s.sum[:0])
+
+	return s.sum, nil
+}
+
+// reset resets the given hash to the state after absorbing the pad
+func (s *macState) reset(h hash.Hash, state []byte) {
+	if s.marshaled {
+		// cannot fail, as the state has been marshaled by the same hash
+		_ = h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state) //nolint:forcetypeassert
+
+		return
+	}
+
+	h.Reset()
+	h.Write(state)
+}
+
+// wipe overwrites all buffers holding key material with zeros and resets the hashes
+func (s *macState) wipe() {
+	otp.Wipe(s.innerState)
+	otp.Wipe(s.outerState)
+	otp.Wipe(s.innerSum[:cap(s.innerSum)])
+	otp.Wipe(s.sum[:cap(s.sum)])
+
+	if s.inner != nil {
+		s.inner.Reset()
+		s.outer.Reset()
+	}
+
+	s.handle = destroyedKey{}
+	s.inner, s.outer = nil, nil
+}
+
+// macPool is a pool of macStates. Since a hash.Hash cannot be used concurrently, each
+// calculation takes its own state from the pool. Unlike sync.Pool, it keeps track of all
+// states created, so that these can be wiped on Destroy.
+type macPool struct {
+	mut       sync.Mutex
+	algorithm otp.HashAlgorithm
+	handle    otp.KeyHandle
+	free      []*macState
+	all       []*macState
+}
+
+func newMACPool(algorithm otp.HashAlgorithm, handle otp.KeyHandle) *macPool {
+	return &macPool{algorithm: algorithm, handle: handle}
+}
+
+func (p *macPool) get() *macState {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if n := len(p.free); n != 0 {
+		state := p.free[n-1]
+		p.free = p.free[:n-1]
+
+		return state
+	}
+
+	state := newMACState(p.algorithm, p.handle)
+	p.all = append(p.all, state)
+
+	return state
+}
+
+func (p *macPool) put(state *macState) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	p.free = append(p.free, state)
+}
+
+// wipe wipes all states created by the pool. Subsequent calculations fail with
+// otp.ErrKeyDestroyed.
+func (p *macPool) wipe() {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	for _, state := range p.all {
+		state.wipe()
+	}
+
+	p.handle = destroyedKey{}
+}
+
+// destroyedKey replaces the key handle of a destroyed algorithm.
+type destroyedKey struct{}
+
+func (destroyedKey) MAC([]byte, otp.HashAlgorithm, []byte) ([]byte, error) {
+	return nil, otp.ErrKeyDestroyed
+}
@@ -0,0 +1,81 @@
+package hotp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/otp"
+)
+
+func TestMACState(t *testing.T) {
+	t.Parallel()
+
+	for _, algorithm := range []otp.HashAlgorithm{otp.SHA1, otp.SHA256, otp.SHA512} {
+		// keys shorter, as long as, and longer than the block size of the hash
+		for _, length := range []int{20, algorithm.Hash().BlockSize(), 200} {
+			algorithm, length := algorithm, length
+
+			t.Run(fmt.Sprintf("%s with %d byte key", algorithm, length), func(t *testing.T) {
+				t.Parallel()
+
+				// GIVEN
+				key := bytes.Repeat([]byte("0123456789"), 20)[:length]
+
+				expected := hmac.New(algorithm.Hash, key)
+				expected.Write([]byte{0, 0, 0, 0, 0, 0, 0, 42})
+
+				marshaled := newMACState(algorithm, otp.MemoryKey(key))
+
+				unmarshaled := &macState{inner: algorithm.Hash(), outer: algorithm.Hash()}
+				unmarshaled.innerState, unmarshaled.outerState = pads(unmarshaled.inner, key)
+
+				// WHEN
+				marshaledSum, err := marshaled.calculate(42)
+				require.NoError(t, err)
+
+				unmarshaledSum, err := unmarshaled.calculate(42)
+				require.NoError(t, err)
+
+				// THEN
+				assert.True(t, marshaled.marshaled)
+				assert.Equal(t, expected.Sum(nil), marshaledSum)
+				assert.Equal(t, expected.Sum(nil), unmarshaledSum)
+			})
+		}
+	}
+}
+
+func TestMACPoolWipe(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	pool := newMACPool(otp.SHA1, otp.MemoryKey("12345678901234567890"))
+
+	first, second := pool.get(), pool.get()
+	_, err := first.calculate(1)
+	require.NoError(t, err)
+
+	pool.put(first)
+	pool.put(second)
+
+	// WHEN
+	pool.wipe()
+
+	// THEN
+	for _, state := range []*macState{first, second} {
+		assert.Equal(t, make([]byte, len(state.innerState)), state.innerState)
+		assert.Equal(t, make([]byte, len(state.outerState)), state.outerState)
+		assert.Equal(t, make([]byte, cap(state.sum)), state.sum[:cap(state.sum)])
+
+		_, err = state.calculate(1)
+		require.ErrorIs(t, err, otp.ErrKeyDestroyed)
+	}
+
+	_, err = pool.get().calculate(1)
+	require.ErrorIs(t, err, otp.ErrKeyDestroyed)
+}
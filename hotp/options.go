@@ -22,6 +22,13 @@ func WithHashAlgorithm(algorithm otp.HashAlgorithm) Option {
 	}
 }
 
+// WithKeyPolicy sets the policy the key is checked against. Defaults to otp.DefaultKeyPolicy.
+func WithKeyPolicy(policy otp.KeyPolicy) Option {
+	return func(alg *Algorithm) {
+		alg.policy = policy
+	}
+}
+
 func WithSkew(skew int) otp.ValidationOption {
 	return func(current int64) otp.SkewIterator { return otp.NewSkewIterator(current, current+int64(skew)+1) }
 }
@@ -0,0 +1,51 @@
+package hotp
+
+// GenerateRange generates count consecutive otp values starting with the given counter,
+// e.g. for printed fallback code lists, or to check imported hardware token seeds.
+// Returns otp.ErrMACFailed if the key handle fails to calculate the HMAC.
+func (a *Algorithm) GenerateRange(counter int64, count int) ([]string, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+
+	values := make([]string, 0, count)
+
+	err := a.GenerateStream(counter, count, func(_ int64, value []byte) bool {
+		values = append(values, string(value))
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// GenerateStream works like GenerateRange, but passes each otp value together with its
+// counter to fn instead of collecting them, which makes it suitable for very large ranges.
+// The generation stops if fn returns false, or with otp.ErrMACFailed if the key handle
+// fails to calculate the HMAC. The value passed to fn is only valid during the call of fn
+// and must be copied to be retained.
+func (a *Algorithm) GenerateStream(counter int64, count int, fn func(counter int64, value []byte) bool) error {
+	state := a.macs.get()
+	defer a.macs.put(state)
+
+	buf := make([]byte, 0, a.digits.Length())
+
+	for i := 0; i < count; i++ {
+		current := counter + int64(i)
+
+		sum, err := state.calculate(current)
+		if err != nil {
+			return err
+		}
+
+		buf = AppendTruncate(buf[:0], a.digits, sum)
+		if !fn(current, buf) {
+			return nil
+		}
+	}
+
+	return nil
+}
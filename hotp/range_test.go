@@ -0,0 +1,60 @@
+package hotp
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRange(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(t, err)
+
+	alg, err := New(secret)
+	require.NoError(t, err)
+
+	// WHEN
+	values, err := alg.GenerateRange(3, 5)
+	empty, emptyErr := alg.GenerateRange(0, 0)
+
+	// THEN
+	// test vectors come from RFC 4226 Appendix D
+	require.NoError(t, err)
+	assert.Equal(t, []string{"969429", "338314", "254676", "287922", "162583"}, values)
+	require.NoError(t, emptyErr)
+	assert.Empty(t, empty)
+}
+
+func TestGenerateStream(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(t, err)
+
+	alg, err := New(secret)
+	require.NoError(t, err)
+
+	var (
+		counters []int64
+		values   []string
+	)
+
+	// WHEN
+	err = alg.GenerateStream(7, 1000000, func(counter int64, value []byte) bool {
+		counters = append(counters, counter)
+		values = append(values, string(value))
+
+		return counter < 9
+	})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7, 8, 9}, counters)
+	assert.Equal(t, []string{"162583", "399871", "520489"}, values)
+}
@@ -0,0 +1,98 @@
+package hotp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dadrus/oath/otp"
+)
+
+// DefaultResyncWindow is the default amount of counters searched by Resync
+const DefaultResyncWindow = 1000
+
+var ErrInvalidResyncValues = errors.New("two or three consecutive otp values required")
+
+// Resync implements the resynchronization as described in RFC 4226, section 7.4. It searches
+// for the given consecutive otp values (two or three) within a large look-ahead window
+// starting at the given counter. The size of the window defaults to DefaultResyncWindow
+// and can be changed by providing WithSkew. On success, the returned result refers to
+// the counter of the last given value.
+func (a *Algorithm) Resync(values []string, counter int64, opts ...otp.ValidationOption) (otp.Result, error) {
+	return a.ResyncContext(context.Background(), values, counter, opts...)
+}
+
+// ResyncContext works like Resync, but aborts the search with otp.ErrCanceled if the given
+// context is done.
+func (a *Algorithm) ResyncContext(
+	ctx context.Context, values []string, counter int64, opts ...otp.ValidationOption,
+) (otp.Result, error) {
+	const (
+		minValues = 2
+		maxValues = 3
+	)
+
+	if len(values) < minValues || len(values) > maxValues {
+		return otp.Result{}, fmt.Errorf("%w: got %d", ErrInvalidResyncValues, len(values))
+	}
+
+	codes := make([][]byte, len(values))
+
+	for idx, value := range values {
+		code := strings.TrimSpace(value)
+		if err := a.checkFormat(code); err != nil {
+			return otp.Result{}, err
+		}
+
+		codes[idx] = []byte(code)
+	}
+
+	if len(opts) == 0 {
+		opts = []otp.ValidationOption{WithSkew(DefaultResyncWindow - 1)}
+	}
+
+	var (
+		window  otp.Window
+		matched int
+		found   int64
+		first   = true
+		buf     [16]byte
+	)
+
+	// all positions are checked to not leak the position of the match via timing
+	for it := opts[0](counter); it.HasNext(); {
+		if err := canceled(ctx); err != nil {
+			return otp.Result{}, err
+		}
+
+		start := it.Value()
+		if first {
+			window.First, first = start, false
+		}
+
+		window.Last = start + int64(len(codes)) - 1
+		match := 1
+
+		for idx, code := range codes {
+			generated, err := a.AppendGenerate(buf[:0], start+int64(idx))
+			if err != nil {
+				return otp.Result{}, err
+			}
+
+			match &= subtle.ConstantTimeCompare(code, generated)
+		}
+
+		found = selectStep(match, start, found)
+		matched |= match
+	}
+
+	if matched == 0 {
+		return otp.Result{}, otp.ErrValidation
+	}
+
+	step := found + int64(len(codes)) - 1
+
+	return otp.Result{Step: step, Deviation: step - counter, Window: window}, nil
+}
@@ -0,0 +1,62 @@
+package hotp
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/otp"
+)
+
+func TestResync(t *testing.T) {
+	t.Parallel()
+
+	// test vectors come from RFC 4226 Appendix D
+
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc      string
+		values  []string
+		counter int64
+		opts    []otp.ValidationOption
+		expStep int64
+		err     error
+	}{
+		{uc: "two consecutive values", values: []string{"254676", "287922"}, counter: 0, expStep: 6},
+		{uc: "three consecutive values", values: []string{"287922", "162583", "399871"}, counter: 1, expStep: 8},
+		{uc: "values at the counter", values: []string{"755224", "287082"}, counter: 0, expStep: 1},
+		{uc: "not consecutive values", values: []string{"254676", "162583"}, counter: 0, err: otp.ErrValidation},
+		{uc: "values in wrong order", values: []string{"287922", "254676"}, counter: 0, err: otp.ErrValidation},
+		{uc: "values behind the counter", values: []string{"254676", "287922"}, counter: 6, err: otp.ErrValidation},
+		{
+			uc: "values outside the window", values: []string{"254676", "287922"}, counter: 0,
+			opts: []otp.ValidationOption{WithSkew(3)}, err: otp.ErrValidation,
+		},
+		{uc: "single value", values: []string{"254676"}, err: ErrInvalidResyncValues},
+		{uc: "too many values", values: []string{"1", "2", "3", "4"}, err: ErrInvalidResyncValues},
+		{uc: "malformed value", values: []string{"254676", "28792a"}, err: otp.ErrMalformed},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			alg, err := New(secret)
+			require.NoError(t, err)
+
+			// WHEN
+			res, err := alg.Resync(tc.values, tc.counter, tc.opts...)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expStep, res.Step)
+				assert.Equal(t, tc.expStep-tc.counter, res.Deviation)
+				assert.Equal(t, otp.Window{First: tc.counter, Last: tc.counter + DefaultResyncWindow + int64(len(tc.values)) - 2}, res.Window)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package hotp
+
+import (
+	"fmt"
+
+	"github.com/dadrus/oath/otp"
+)
+
+// Destroy overwrites the key with zeros, if it is held in memory (otp.MemoryKey), as well as
+// the cached HMAC states derived from it. Handles of keys held elsewhere are not touched.
+// Afterwards, no otp values can be generated anymore and the validation fails with
+// otp.ErrKeyDestroyed. Destroy must not be called concurrently with other methods.
+func (a *Algorithm) Destroy() {
+	if key, ok := a.key.(otp.MemoryKey); ok {
+		key.Destroy()
+	}
+
+	a.key = destroyedKey{}
+	a.macs.wipe()
+}
+
+func (a Algorithm) String() string {
+	return fmt.Sprintf("HOTP(%s, %d digits)", a.algorithm, a.digits)
+}
+
+func (a Algorithm) GoString() string {
+	return fmt.Sprintf("hotp.Algorithm{key: %s, digits: %d, algorithm: %q}", otp.Redacted, a.digits, a.algorithm)
+}
+
+func (a Algorithm) Format(f fmt.State, verb rune) { otp.FormatRedacted(f, verb, a) }
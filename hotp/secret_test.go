@@ -0,0 +1,42 @@
+package hotp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/otp"
+)
+
+func TestDestroy(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := otp.MemoryKey("12345678901234567890")
+
+	alg, err := NewFromHandle(key)
+	require.NoError(t, err)
+
+	// WHEN
+	alg.Destroy()
+
+	// THEN
+	assert.Equal(t, make(otp.MemoryKey, 20), key)
+	assert.Nil(t, alg.Key())
+	assert.Empty(t, alg.Generate(0))
+
+	_, err = alg.Validate("755224", 0)
+	require.ErrorIs(t, err, otp.ErrKeyDestroyed)
+}
+
+func TestAlgorithmFormat(t *testing.T) {
+	t.Parallel()
+
+	alg, err := New([]byte("12345678901234567890"), WithHashAlgorithm(otp.SHA256))
+	require.NoError(t, err)
+
+	assert.Equal(t, "HOTP(SHA256, 6 digits)", fmt.Sprintf("%x", alg))
+	assert.Equal(t, `hotp.Algorithm{key: [REDACTED], digits: 6, algorithm: "SHA256"}`, fmt.Sprintf("%#v", *alg))
+}
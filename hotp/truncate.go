@@ -9,6 +9,16 @@ import (
 // Truncate implements the "dynamic truncation" as defined in RFC 4226
 // See http://tools.ietf.org/html/rfc4226#section-5.4 for details
 func Truncate(digits otp.Digits, sum []byte) string {
+	return digits.Format(truncate(digits, sum))
+}
+
+// AppendTruncate works like Truncate, but appends the otp value to dst and returns the
+// extended buffer.
+func AppendTruncate(dst []byte, digits otp.Digits, sum []byte) []byte {
+	return digits.AppendFormat(dst, truncate(digits, sum))
+}
+
+func truncate(digits otp.Digits, sum []byte) int32 {
 	//nolint:gomnd
 	offset := sum[len(sum)-1] & 0xf
 	//nolint:gomnd
@@ -17,7 +27,5 @@ func Truncate(digits otp.Digits, sum []byte) string {
 		((int(sum[offset+2] & 0xff)) << 8) |
 		(int(sum[offset+3]) & 0xff))
 
-	otp := int32(value % int64(math.Pow10(digits.Length())))
-
-	return digits.Format(otp)
+	return int32(value % int64(math.Pow10(digits.Length())))
 }
@@ -0,0 +1,52 @@
+package oath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/otp"
+)
+
+func TestHOTPBlobVerify(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("12345678901234567890")
+	alg := newHOTP(t, key)
+
+	for _, tc := range []struct {
+		uc         string
+		value      string
+		err        error
+		expCounter int64
+	}{
+		{uc: "valid value", value: alg.Generate(5), expCounter: 6},
+		{uc: "valid value within skew", value: alg.Generate(6), expCounter: 7},
+		{uc: "wrong value", value: "000000", err: otp.ErrValidation, expCounter: 5},
+		{uc: "already used value", value: alg.Generate(4), err: otp.ErrReplayed, expCounter: 5},
+		{uc: "value ahead of the window", value: alg.Generate(10), err: otp.ErrOutOfWindow, expCounter: 5},
+		{uc: "too short value", value: "12345", err: otp.ErrInvalidLength, expCounter: 5},
+		{uc: "not numeric value", value: "12345a", err: otp.ErrMalformed, expCounter: 5},
+		{uc: "not numeric value matching validation error", value: "12345a", err: otp.ErrValidation, expCounter: 5},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			blb := &hotpBlob{c: &config{Key: key, Type: "hotp", Counter: 5, WorkSkew: 1, InitialSkew: 1}}
+
+			// WHEN
+			err := blb.Verify(tc.value)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				assert.False(t, blb.Synchronized())
+			} else {
+				require.NoError(t, err)
+				assert.True(t, blb.Synchronized())
+			}
+
+			assert.Equal(t, tc.expCounter, blb.c.Counter)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package oath
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMasterSecretRequired is returned if the key of a blob is derived from a master secret,
+	// but no master secret has been provided via WithMasterSecret.
+	ErrMasterSecretRequired = errors.New("master secret required")
+	// ErrConflictingKeyOptions is returned by New if more than one of WithKey,
+	// WithKeyDerivation and WithKeyReference are used.
+	ErrConflictingKeyOptions = errors.New("conflicting key options")
+	// ErrInvalidKeyLength is returned if the length of a derived key is not positive, or
+	// exceeds the maximum length HKDF is able to derive.
+	ErrInvalidKeyLength = errors.New("invalid key length")
+)
+
+const (
+	keyDerivationNonceSize = 16
+	// maxDerivedKeyLength is the maximum length of the output of HKDF (RFC 5869, section 2.3)
+	maxDerivedKeyLength = 255 * sha256.Size
+)
+
+// keyDerivation holds the inputs used to derive the otp key from the master secret
+type keyDerivation struct {
+	Context string `json:"context"`
+	Nonce   []byte `json:"nonce"`
+	Length  int    `json:"length"`
+}
+
+// WithKeyDerivation makes New derive the otp key from a master secret (see WithMasterSecret)
+// using HKDF instead of generating a random key. The given context identifies the account,
+// e.g. the id of the user. Together with a random nonce created on enrollment, it is stored
+// in the blob instead of the key. This way the key can be recreated as long as the master
+// secret is available, e.g. from a vault.
+func WithKeyDerivation(context string) Option {
+	return func(o *config) {
+		o.KeyDerivation = &keyDerivation{Context: context}
+	}
+}
+
+// WithMasterSecret sets the master secret used to derive the keys of blobs created with
+// WithKeyDerivation. It is never stored in the blob and must be provided to all functions
+// operating on such blobs, like New, Verify, Export or Open.
+func WithMasterSecret(secret []byte) Option {
+	return func(o *config) {
+		o.settings.masterSecret = bytes.Clone(secret)
+	}
+}
+
+// deriveKey derives the otp key from the master secret, if the blob makes use of key
+// derivation.
+func (b *config) deriveKey() error {
+	if b.KeyDerivation == nil {
+		return nil
+	}
+
+	if len(b.settings.masterSecret) == 0 {
+		return ErrMasterSecretRequired
+	}
+
+	if length := b.KeyDerivation.Length; length <= 0 || length > maxDerivedKeyLength {
+		return fmt.Errorf("%w: %d", ErrInvalidKeyLength, length)
+	}
+
+	info := []byte("oath otp key:" + b.Type + ":" + b.KeyDerivation.Context)
+
+	b.Key = hkdf(sha256.New, b.settings.masterSecret, b.KeyDerivation.Nonce, info, b.KeyDerivation.Length)
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package oath
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/otp"
+)
+
+func TestHKDF(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN (RFC 5869, test case 1)
+	ikm, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+
+	// WHEN
+	okm := hkdf(sha256.New, ikm, salt, info, 42)
+
+	// THEN
+	assert.Equal(t,
+		"3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865",
+		hex.EncodeToString(okm))
+}
+
+func TestKeyDerivation(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	c := newAEAD(t)
+	master := []byte("a very secret master secret")
+
+	blob, err := TOTP.New(c, WithKeyDerivation("alice"), WithMasterSecret(master))
+	require.NoError(t, err)
+
+	var data config
+	require.NoError(t, data.unmarshal(blob, c))
+
+	_, encodedKey, err := Export(blob, c, "alice", "foo", WithMasterSecret(master))
+	require.NoError(t, err)
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(encodedKey)
+	require.NoError(t, err)
+
+	value := newTOTP(t, key).Generate(time.Now().Unix())
+
+	for _, tc := range []struct {
+		uc   string
+		opts []Option
+		err  error
+	}{
+		{uc: "without master secret", err: ErrMasterSecretRequired},
+		{uc: "with wrong master secret", opts: []Option{WithMasterSecret([]byte("foo"))}, err: otp.ErrValidation},
+		{uc: "with master secret", opts: []Option{WithMasterSecret(master)}},
+	} {
+		tc := tc
+
+		t.Run(tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			_, _, err := Verify(value, blob, c, tc.opts...)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	// the key is not stored in the blob
+	assert.Empty(t, data.Key)
+	require.NotNil(t, data.KeyDerivation)
+	assert.Equal(t, "alice", data.KeyDerivation.Context)
+	assert.Len(t, data.KeyDerivation.Nonce, keyDerivationNonceSize)
+	assert.Len(t, key, 20)
+}
+
+func TestNewWithKeyDerivationFails(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc   string
+		opts []Option
+		err  error
+	}{
+		{uc: "without master secret", opts: []Option{WithKeyDerivation("alice")}, err: ErrMasterSecretRequired},
+		{
+			uc:   "with key",
+			opts: []Option{WithKeyDerivation("alice"), WithMasterSecret([]byte("foo")), WithKey([]byte("bar"))},
+			err:  ErrConflictingKeyOptions,
+		},
+		{
+			uc:   "with key length exceeding the hkdf limit",
+			opts: []Option{WithKeyDerivation("alice"), WithMasterSecret([]byte("foo")), WithKeyLength(9000)},
+			err:  ErrInvalidKeyLength,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			_, err := HOTP.New(newAEAD(t), tc.opts...)
+
+			// THEN
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package oath
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/dadrus/oath/otp"
+)
+
+var (
+	// ErrKeyProviderRequired is returned if the key of a blob is kept by a key provider,
+	// but no key provider has been configured via WithKeyProvider.
+	ErrKeyProviderRequired = errors.New("key provider required")
+	// ErrKeyNotExportable is returned by Export if the key of a blob is kept by a key
+	// provider and thus cannot be revealed.
+	ErrKeyNotExportable = errors.New("key not exportable")
+)
+
+// KeyProvider provides handles to keys kept outside the blob, like in a PKCS#11 token
+// or an HSM.
+type KeyProvider interface {
+	// KeyHandle returns the handle of the key with the given reference.
+	KeyHandle(reference string) (otp.KeyHandle, error)
+}
+
+// WithKeyReference makes New store the given reference to a key kept by a key provider
+// (see WithKeyProvider) in the blob instead of a key. The key must be provisioned in the
+// backend of the key provider separately and is not checked against the key policy.
+func WithKeyReference(reference string) Option {
+	return func(o *config) {
+		o.KeyReference = reference
+	}
+}
+
+// WithKeyProvider sets the key provider used to calculate the otp values of blobs created
+// with WithKeyReference. It must be provided to all functions operating on such blobs,
+// like Verify or Open.
+func WithKeyProvider(provider KeyProvider) Option {
+	return func(o *config) {
+		if provider != nil {
+			o.settings.keyProvider = provider
+		}
+	}
+}
+
+// keyHandle returns the handle to calculate the otp values with. This is either a copy of
+// the key held by the blob, which is wiped when the algorithm using it is destroyed, or the
+// key kept by the key provider. Keys of existing blobs cannot be replaced. They have been
+// checked by New already, so the key policy is not applied to the returned handle.
+func (b *config) keyHandle() (otp.KeyHandle, error) {
+	if len(b.KeyReference) == 0 {
+		return otp.MemoryKey(bytes.Clone(b.Key)), nil
+	}
+
+	if b.settings.keyProvider == nil {
+		return nil, ErrKeyProviderRequired
+	}
+
+	handle, err := b.settings.keyProvider.KeyHandle(b.KeyReference)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", otp.ErrMACFailed, err)
+	}
+
+	return handle, nil
+}
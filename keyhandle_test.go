@@ -0,0 +1,125 @@
+package oath
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/oathtest"
+	"github.com/dadrus/oath/otp"
+)
+
+func TestKeyProvider(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+
+	hsm := oathtest.NewHSM()
+	hsm.Import("alice", key)
+
+	unavailable := oathtest.NewHSM()
+	unavailable.Import("alice", key)
+	unavailable.SetAvailable(false)
+
+	blob, err := TOTP.New(c, WithKeyReference("alice"))
+	require.NoError(t, err)
+
+	value := newTOTP(t, key).Generate(time.Now().Unix())
+
+	for _, tc := range []struct {
+		uc   string
+		opts []Option
+		err  error
+	}{
+		{uc: "without key provider", err: ErrKeyProviderRequired},
+		{uc: "with unknown key", opts: []Option{WithKeyProvider(oathtest.NewHSM())}, err: oathtest.ErrKeyNotFound},
+		{uc: "with unavailable key provider", opts: []Option{WithKeyProvider(unavailable)}, err: otp.ErrMACFailed},
+		{uc: "with key provider", opts: []Option{WithKeyProvider(hsm)}},
+	} {
+		tc := tc
+
+		t.Run(tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			_, synced, err := Verify(value, blob, c, tc.opts...)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				assert.False(t, synced)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, synced)
+			}
+		})
+	}
+
+	t.Run("key is not exported", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := Export(blob, c, "alice", "foo", WithKeyProvider(hsm))
+		require.ErrorIs(t, err, ErrKeyNotExportable)
+	})
+}
+
+func TestNewWithKeyReferenceFails(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc   string
+		opts []Option
+	}{
+		{uc: "with key", opts: []Option{WithKeyReference("alice"), WithKey([]byte("12345678901234567890"))}},
+		{uc: "with key derivation", opts: []Option{WithKeyReference("alice"), WithKeyDerivation("alice")}},
+	} {
+		tc := tc
+
+		t.Run(tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			_, err := HOTP.New(newAEAD(t), tc.opts...)
+
+			// THEN
+			require.ErrorIs(t, err, ErrConflictingKeyOptions)
+		})
+	}
+}
+
+func TestKeyProviderOutageDoesNotLockBlob(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+
+	hsm := oathtest.NewHSM()
+	hsm.Import("alice", key)
+	hsm.SetAvailable(false)
+
+	blob, err := TOTP.New(c, WithKeyReference("alice"), WithLockout(3))
+	require.NoError(t, err)
+
+	value := newTOTP(t, key).Generate(time.Now().Unix())
+
+	// WHEN
+	for i := 0; i < 5; i++ {
+		updated, _, err := Verify(value, blob, c, WithKeyProvider(hsm))
+
+		// THEN
+		require.ErrorIs(t, err, otp.ErrMACFailed)
+		require.NotErrorIs(t, err, ErrLocked)
+		assert.Equal(t, blob, updated)
+	}
+
+	hsm.SetAvailable(true)
+
+	_, synced, err := Verify(value, blob, c, WithKeyProvider(hsm))
+	require.NoError(t, err)
+	assert.True(t, synced)
+}
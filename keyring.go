@@ -0,0 +1,116 @@
+package oath
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+var ErrUnknownKey = errors.New("unknown key id")
+
+type KeyringOption func(k *Keyring)
+
+// WithRetiredKey adds a key, which is no longer used to seal blobs, but is still required
+// to unseal blobs, which have been sealed with it in the past.
+func WithRetiredKey(id string, key cipher.AEAD) KeyringOption {
+	return func(k *Keyring) {
+		if _, present := k.keys[id]; !present {
+			k.ids = append(k.ids, id)
+		}
+
+		k.keys[id] = key
+	}
+}
+
+// Keyring holds the AEAD ciphers used to protect blobs, each identified by a key id.
+// Blobs are always sealed with the current key. The key id is stored in the sealed blob,
+// so that blobs sealed with a retired key can still be unsealed. These are re-sealed
+// with the current key on the next successful verification.
+//
+// Keyring implements cipher.AEAD itself, so it can be used everywhere a cipher.AEAD is
+// expected. When used directly in that way, Seal makes use of the current key and Open
+// tries all known keys, starting with the current one.
+type Keyring struct {
+	current string
+	ids     []string
+	keys    map[string]cipher.AEAD
+}
+
+func NewKeyring(id string, current cipher.AEAD, opts ...KeyringOption) *Keyring {
+	ring := &Keyring{
+		current: id,
+		ids:     []string{id},
+		keys:    map[string]cipher.AEAD{id: current},
+	}
+
+	for _, opt := range opts {
+		opt(ring)
+	}
+
+	// the current key might have been overridden by an option
+	ring.keys[id] = current
+
+	return ring
+}
+
+// CurrentKeyID returns the id of the key used to seal blobs
+func (k *Keyring) CurrentKeyID() string { return k.current }
+
+// Key returns the key for the given id
+func (k *Keyring) Key(id string) (cipher.AEAD, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+func (k *Keyring) NonceSize() int { return k.keys[k.current].NonceSize() }
+
+func (k *Keyring) Overhead() int { return k.keys[k.current].Overhead() }
+
+func (k *Keyring) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	return k.keys[k.current].Seal(dst, nonce, plaintext, additionalData)
+}
+
+func (k *Keyring) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	var err error
+
+	for _, id := range k.ids {
+		key := k.keys[id]
+		if len(nonce) != key.NonceSize() {
+			continue
+		}
+
+		var res []byte
+		if res, err = key.Open(dst, nonce, ciphertext, additionalData); err == nil {
+			return res, nil
+		}
+	}
+
+	if err == nil {
+		err = ErrInvalidBlob
+	}
+
+	return nil, err
+}
+
+// key returns the key for the given id. Blobs sealed with a plain cipher.AEAD carry an
+// empty key id. Unless a key has been registered with the empty id, all known keys are
+// tried to open these, like for blobs without key id.
+func (k *Keyring) key(id string) (cipher.AEAD, error) {
+	key, err := k.Key(id)
+	if err != nil && len(id) == 0 {
+		return k, nil
+	}
+
+	return key, err
+}
+
+func keyring(c cipher.AEAD) *Keyring {
+	if ring, ok := c.(*Keyring); ok {
+		return ring
+	}
+
+	return NewKeyring("", c)
+}
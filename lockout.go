@@ -0,0 +1,160 @@
+package oath
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrLocked = errors.New("blob locked")
+
+// LockedError is returned by Verify if the blob is locked due to too many consecutive
+// failed verification attempts. It matches ErrLocked.
+type LockedError struct {
+	// RetryAfter is the point in time, from which on the next verification attempt
+	// is possible. It is the zero time if the blob is locked until it is unlocked
+	// explicitly by making use of Unlock.
+	RetryAfter time.Time
+}
+
+func (e *LockedError) Error() string {
+	if e.RetryAfter.IsZero() {
+		return ErrLocked.Error()
+	}
+
+	return fmt.Sprintf("%s: retry after %s", ErrLocked, e.RetryAfter.Format(time.RFC3339))
+}
+
+func (e *LockedError) Unwrap() error { return ErrLocked }
+
+type lockout struct {
+	MaxAttempts  int           `json:"max_attempts,omitempty"`
+	FreeAttempts int           `json:"free_attempts,omitempty"`
+	Backoff      time.Duration `json:"backoff,omitempty"`
+	MaxBackoff   time.Duration `json:"max_backoff,omitempty"`
+}
+
+// WithBackoff configures an exponential backoff. After freeAttempts consecutive failed
+// verifications, each further failure locks the blob, starting with the duration given
+// by backoff, which is doubled on each subsequent failure, but does not exceed maxBackoff.
+// A maxBackoff of 0 means, there is no upper limit.
+func WithBackoff(freeAttempts int, backoff, maxBackoff time.Duration) Option {
+	return func(o *config) {
+		if backoff <= 0 {
+			return
+		}
+
+		if o.Lockout == nil {
+			o.Lockout = &lockout{}
+		}
+
+		o.Lockout.FreeAttempts = freeAttempts
+		o.Lockout.Backoff = backoff
+		o.Lockout.MaxBackoff = maxBackoff
+	}
+}
+
+// WithLockout configures a hard lockout. After maxAttempts consecutive failed verifications
+// the blob is locked until it is unlocked explicitly by making use of Unlock.
+func WithLockout(maxAttempts int) Option {
+	return func(o *config) {
+		if maxAttempts <= 0 {
+			return
+		}
+
+		if o.Lockout == nil {
+			o.Lockout = &lockout{}
+		}
+
+		o.Lockout.MaxAttempts = maxAttempts
+	}
+}
+
+// mergeLockout merges the lockout policy given while opening the blob into the one stored
+// in the blob. The backoff, respectively the hard lockout are only overridden if given.
+func (b *config) mergeLockout() {
+	override := b.settings.lockout
+	if override == nil {
+		return
+	}
+
+	if b.Lockout == nil {
+		b.Lockout = &lockout{}
+	}
+
+	if override.Backoff != 0 {
+		b.Lockout.FreeAttempts = override.FreeAttempts
+		b.Lockout.Backoff = override.Backoff
+		b.Lockout.MaxBackoff = override.MaxBackoff
+	}
+
+	if override.MaxAttempts != 0 {
+		b.Lockout.MaxAttempts = override.MaxAttempts
+	}
+}
+
+// Unlock resets the failed verification attempts tracked in the blob and by this removes
+// any lock. It returns the updated sealed blob.
+func Unlock(blobValue string, cipher cipher.AEAD, opts ...Option) (string, error) {
+	tok, err := Open(blobValue, cipher, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	defer tok.Destroy()
+
+	tok.Unlock()
+
+	return tok.Seal(cipher)
+}
+
+func (b *config) checkLocked(now time.Time) error {
+	if b.Lockout == nil {
+		return nil
+	}
+
+	if b.Lockout.MaxAttempts != 0 && b.FailedAttempts >= b.Lockout.MaxAttempts {
+		return &LockedError{}
+	}
+
+	if b.LockedUntil != 0 && now.UnixMilli() < b.LockedUntil {
+		return &LockedError{RetryAfter: time.UnixMilli(b.LockedUntil)}
+	}
+
+	return nil
+}
+
+func (b *config) registerFailure(now time.Time) {
+	if b.Lockout == nil {
+		return
+	}
+
+	b.FailedAttempts++
+
+	if b.Lockout.Backoff == 0 || b.FailedAttempts <= b.Lockout.FreeAttempts {
+		return
+	}
+
+	delay := b.Lockout.Backoff
+
+	for i := b.Lockout.FreeAttempts + 1; i < b.FailedAttempts; i++ {
+		// stop doubling, if the max backoff has been reached, or the delay would overflow
+		if (b.Lockout.MaxBackoff != 0 && delay >= b.Lockout.MaxBackoff) || delay > delay<<1 {
+			break
+		}
+
+		delay <<= 1
+	}
+
+	if b.Lockout.MaxBackoff != 0 && delay > b.Lockout.MaxBackoff {
+		delay = b.Lockout.MaxBackoff
+	}
+
+	b.LockedUntil = now.Add(delay).UnixMilli()
+}
+
+func (b *config) resetFailures() {
+	b.FailedAttempts = 0
+	b.LockedUntil = 0
+}
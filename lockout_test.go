@@ -0,0 +1,136 @@
+package oath
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/oathtest"
+	"github.com/dadrus/oath/otp"
+)
+
+func TestConfigBackoff(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	now := time.Unix(1000, 0)
+	cfg := &config{}
+	WithBackoff(2, time.Second, 5*time.Second)(cfg)
+
+	for _, exp := range []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		// WHEN
+		cfg.registerFailure(now)
+
+		// THEN
+		if exp == 0 {
+			require.NoError(t, cfg.checkLocked(now))
+		} else {
+			var lErr *LockedError
+
+			require.ErrorAs(t, cfg.checkLocked(now), &lErr)
+			assert.Equal(t, now.Add(exp), lErr.RetryAfter)
+			require.NoError(t, cfg.checkLocked(now.Add(exp)))
+		}
+	}
+
+	cfg.resetFailures()
+	require.NoError(t, cfg.checkLocked(now))
+}
+
+func TestVerifyWithLockout(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+	alg := newHOTP(t, key)
+
+	blob, err := HOTP.New(c, WithKey(key), WithLockout(2))
+	require.NoError(t, err)
+
+	// WHEN
+	blob, _, err = Verify("000000", blob, c)
+	require.ErrorIs(t, err, otp.ErrValidation)
+	require.NotEmpty(t, blob)
+
+	blob, _, err = Verify("000000", blob, c)
+	require.ErrorIs(t, err, otp.ErrValidation)
+	require.NotEmpty(t, blob)
+
+	// THEN
+	var lErr *LockedError
+
+	_, _, err = Verify(alg.Generate(0), blob, c)
+	require.ErrorIs(t, err, ErrLocked)
+	require.ErrorAs(t, err, &lErr)
+	assert.True(t, lErr.RetryAfter.IsZero())
+
+	blob, err = Unlock(blob, c)
+	require.NoError(t, err)
+
+	_, synced, err := Verify(alg.Generate(0), blob, c)
+	require.NoError(t, err)
+	assert.True(t, synced)
+}
+
+func TestVerifyWithLockoutGivenAtVerification(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN -> a blob created without lockout, but with backoff
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+	clock := oathtest.NewClock(time.Unix(1000, 0))
+
+	blob, err := HOTP.New(c, WithKey(key), WithBackoff(5, time.Second, 0))
+	require.NoError(t, err)
+
+	// WHEN
+	for i := 0; i < 2; i++ {
+		blob, _, err = Verify("000000", blob, c, WithLockout(2), WithClock(clock))
+		require.ErrorIs(t, err, otp.ErrValidation)
+	}
+
+	// THEN -> the lockout applies, even if not given anymore
+	_, _, err = Verify(newHOTP(t, key).Generate(0), blob, c, WithClock(clock))
+	require.ErrorIs(t, err, ErrLocked)
+
+	tok, err := Open(blob, c)
+	require.NoError(t, err)
+
+	// the backoff stored in the blob is kept
+	assert.Equal(t, &lockout{MaxAttempts: 2, FreeAttempts: 5, Backoff: time.Second}, tok.data.Lockout)
+}
+
+func TestVerifyWithBackoff(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+	clock := oathtest.NewClock(time.Unix(1111111109, 0))
+	value := newTOTP(t, key).Generate(1111111109 + 60)
+
+	blob, err := TOTP.New(c, WithKey(key), WithBackoff(0, time.Minute, 0))
+	require.NoError(t, err)
+
+	blob, _, err = Verify("000000", blob, c, WithClock(clock))
+	require.ErrorIs(t, err, otp.ErrValidation)
+
+	// WHEN
+	_, _, err = Verify(value, blob, c, WithClock(clock))
+
+	// THEN
+	var lErr *LockedError
+
+	require.ErrorAs(t, err, &lErr)
+	assert.Equal(t, time.Unix(1111111109+60, 0), lErr.RetryAfter)
+
+	// WHEN
+	clock.Advance(time.Minute)
+	_, _, err = Verify(value, blob, c, WithClock(clock))
+
+	// THEN
+	require.NoError(t, err)
+}
@@ -0,0 +1,40 @@
+// Package oathtest provides helpers for testing code making use of the oath package
+package oathtest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a fake clock implementing oath.Clock. It only moves if told to do so.
+// It is safe for concurrent use.
+type Clock struct {
+	mut sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a fake clock set to the given time
+func NewClock(now time.Time) *Clock { return &Clock{now: now} }
+
+func (c *Clock) Now() time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	return c.now
+}
+
+// Set sets the clock to the given time
+func (c *Clock) Set(now time.Time) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.now = now
+}
+
+// Advance moves the clock by the given duration, which can also be negative
+func (c *Clock) Advance(d time.Duration) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.now = c.now.Add(d)
+}
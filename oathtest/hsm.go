@@ -0,0 +1,86 @@
+package oathtest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dadrus/oath/otp"
+)
+
+var (
+	// ErrKeyNotFound is returned by HSM if no key with the given reference exists.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrHSMUnavailable is returned by HSM after SetAvailable(false) has been called.
+	ErrHSMUnavailable = errors.New("hsm unavailable")
+)
+
+// HSM is a software stand-in for a hardware security module implementing oath.KeyProvider.
+// The key handles it provides never reveal the keys. It is safe for concurrent use.
+type HSM struct {
+	mut         sync.Mutex
+	keys        map[string][]byte
+	unavailable bool
+	calls       int
+}
+
+// NewHSM creates an empty HSM
+func NewHSM() *HSM { return &HSM{keys: make(map[string][]byte)} }
+
+// Import stores the given key under the given reference
+func (h *HSM) Import(reference string, key []byte) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	h.keys[reference] = bytes.Clone(key)
+}
+
+// SetAvailable lets all subsequent MAC calculations fail with ErrHSMUnavailable if set to false
+func (h *HSM) SetAvailable(available bool) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	h.unavailable = !available
+}
+
+// Calls returns the number of MAC calculations done so far
+func (h *HSM) Calls() int {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	return h.calls
+}
+
+func (h *HSM) KeyHandle(reference string) (otp.KeyHandle, error) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	if _, ok := h.keys[reference]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, reference)
+	}
+
+	return &hsmKey{hsm: h, reference: reference}, nil
+}
+
+type hsmKey struct {
+	hsm       *HSM
+	reference string
+}
+
+func (k *hsmKey) MAC(dst []byte, algorithm otp.HashAlgorithm, message []byte) ([]byte, error) {
+	k.hsm.mut.Lock()
+	defer k.hsm.mut.Unlock()
+
+	if k.hsm.unavailable {
+		return nil, ErrHSMUnavailable
+	}
+
+	k.hsm.calls++
+
+	mac := hmac.New(algorithm.Hash, k.hsm.keys[k.reference])
+	mac.Write(message)
+
+	return mac.Sum(dst), nil
+}
@@ -0,0 +1,34 @@
+package ocra
+
+import "time"
+
+// Input holds the data input for the OCRA computation. Which of the fields are
+// required depends on the suite used.
+type Input struct {
+	// Counter is used if the suite contains the C parameter
+	Counter int64
+
+	// Question is the challenge. It must be a decimal number for QN, a hex string
+	// for QH and an arbitrary string for QA suites, which is not longer than defined
+	// by the suite
+	Question string
+
+	// PeerQuestion is the challenge of the other party in the mutual challenge-response
+	// mode (see RFC 6287, section 7.3). If set, it is appended to Question. It has to
+	// fulfill the same requirements as Question.
+	PeerQuestion string
+
+	// PIN is the plain PIN. It is hashed according to the P parameter of the suite.
+	// If PINHash is set, PIN is ignored.
+	PIN string
+
+	// PINHash is the already hashed PIN.
+	PINHash []byte
+
+	// Session is the session information used if the suite contains the S parameter.
+	// It is left padded with zeros up to the length defined in the suite
+	Session []byte
+
+	// Timestamp is used and required if the suite contains the T parameter
+	Timestamp time.Time
+}
@@ -0,0 +1,240 @@
+// Package ocra implements the OATH Challenge-Response Algorithm according to RFC 6287
+package ocra
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/dadrus/oath/hotp"
+	"github.com/dadrus/oath/otp"
+)
+
+var (
+	ErrInvalidQuestion  = errors.New("invalid question")
+	ErrInvalidSession   = errors.New("invalid session information")
+	ErrInvalidPIN       = errors.New("invalid pin")
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+)
+
+// SuiteExporter can optionally be implemented by an otp.Exporter to receive
+// the OCRA suite string while exporting an Algorithm
+type SuiteExporter interface {
+	SetSuite(suite string)
+}
+
+type Algorithm struct {
+	key       []byte
+	suite     *Suite
+	destroyed bool
+}
+
+// New creates an OCRA algorithm instance for the given key and suite string
+// (e.g. OCRA-1:HOTP-SHA1-6:QN08)
+func New(key []byte, suite string) (*Algorithm, error) {
+	parsed, err := ParseSuite(suite)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Algorithm{key: bytes.Clone(key), suite: parsed}, nil
+}
+
+func (a *Algorithm) Key() []byte { return bytes.Clone(a.key) }
+
+func (a *Algorithm) Suite() *Suite { return a.suite }
+
+// Generate computes the OCRA response for the given input. Only the parts of the input,
+// referenced by the suite are taken into account. Returns otp.ErrKeyDestroyed if the
+// algorithm has been destroyed.
+func (a *Algorithm) Generate(input Input) (string, error) {
+	if a.destroyed {
+		return "", otp.ErrKeyDestroyed
+	}
+
+	message, err := a.message(input)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(a.suite.hashAlgorithm.Hash, a.key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	if a.suite.digits == 0 {
+		return hex.EncodeToString(sum), nil
+	}
+
+	return hotp.Truncate(a.suite.digits, sum), nil
+}
+
+// Validate validates the given response for the given input
+func (a *Algorithm) Validate(value string, input Input) error {
+	response := strings.TrimSpace(value)
+
+	expected, err := a.Generate(input)
+	if err != nil {
+		return err
+	}
+
+	if len(response) != len(expected) {
+		return fmt.Errorf("%w: %d", otp.ErrInvalidLength, len(response))
+	}
+
+	if subtle.ConstantTimeCompare([]byte(response), []byte(expected)) == 0 {
+		return otp.ErrValidation
+	}
+
+	return nil
+}
+
+func (a *Algorithm) Export(exporter otp.Exporter) {
+	exporter.SetAlgorithm("ocra")
+	exporter.SetDigits(a.suite.digits)
+	exporter.SetKey(a.key)
+	exporter.SetHashAlgorithm(a.suite.hashAlgorithm)
+
+	if a.suite.timeStep != 0 {
+		exporter.SetPeriod(a.suite.timeStep)
+	}
+
+	if exp, ok := exporter.(SuiteExporter); ok {
+		exp.SetSuite(a.suite.value)
+	}
+}
+
+func (a *Algorithm) message(input Input) ([]byte, error) {
+	const (
+		intSize        = 8
+		questionLength = 128
+	)
+
+	// See https://www.rfc-editor.org/rfc/rfc6287#section-5.1 for the structure of the message
+	buf := bytes.NewBufferString(a.suite.value)
+	buf.WriteByte(0)
+
+	if a.suite.counter {
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(input.Counter)))
+	}
+
+	question, err := a.question(input.Question, input.PeerQuestion)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.Write(question)
+	buf.Write(make([]byte, questionLength-len(question)))
+
+	if len(a.suite.pinHash) != 0 {
+		pin, err := a.pin(input)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(pin)
+	}
+
+	if a.suite.sessionLength != 0 {
+		if len(input.Session) > a.suite.sessionLength {
+			return nil, fmt.Errorf("%w: expected at most %d bytes, got %d",
+				ErrInvalidSession, a.suite.sessionLength, len(input.Session))
+		}
+
+		// session information is left padded with zeros
+		buf.Write(make([]byte, a.suite.sessionLength-len(input.Session)))
+		buf.Write(input.Session)
+	}
+
+	if a.suite.timeStep != 0 {
+		if input.Timestamp.IsZero() {
+			return nil, fmt.Errorf("%w: timestamp is missing", ErrInvalidTimestamp)
+		}
+
+		steps := input.Timestamp.Unix() / int64(a.suite.timeStep.Seconds())
+
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(steps)))
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (a *Algorithm) question(value, peer string) ([]byte, error) {
+	const maxQuestionBytes = 128
+
+	if len(value) == 0 {
+		return nil, fmt.Errorf("%w: question is empty", ErrInvalidQuestion)
+	}
+
+	for _, challenge := range []string{value, peer} {
+		if len(challenge) > a.suite.questionLength {
+			return nil, fmt.Errorf("%w: expected at most %d characters, got %d",
+				ErrInvalidQuestion, a.suite.questionLength, len(challenge))
+		}
+	}
+
+	value += peer
+
+	var (
+		question []byte
+		err      error
+	)
+
+	switch a.suite.questionFormat {
+	case QuestionNumeric:
+		number, ok := new(big.Int).SetString(value, 10) //nolint:gomnd
+		if !ok || number.Sign() < 0 {
+			return nil, fmt.Errorf("%w: %s is not numeric", ErrInvalidQuestion, value)
+		}
+
+		question, err = leftAlignedHex(number.Text(16)) //nolint:gomnd
+	case QuestionHex:
+		question, err = leftAlignedHex(value)
+	default:
+		question = []byte(value)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is not hex encoded", ErrInvalidQuestion, value)
+	}
+
+	if len(question) > maxQuestionBytes {
+		return nil, fmt.Errorf("%w: question is too long", ErrInvalidQuestion)
+	}
+
+	return question, nil
+}
+
+func (a *Algorithm) pin(input Input) ([]byte, error) {
+	if len(input.PINHash) != 0 {
+		if len(input.PINHash) != a.suite.pinHash.Size() {
+			return nil, fmt.Errorf("%w: unexpected pin hash length %d", ErrInvalidPIN, len(input.PINHash))
+		}
+
+		return input.PINHash, nil
+	}
+
+	if len(input.PIN) == 0 {
+		return nil, fmt.Errorf("%w: pin is missing", ErrInvalidPIN)
+	}
+
+	hash := a.suite.pinHash.Hash()
+	hash.Write([]byte(input.PIN))
+
+	return hash.Sum(nil), nil
+}
+
+// leftAlignedHex decodes the given hex string. If the string has an odd length,
+// it is padded with a trailing zero, as the question is left aligned in the message
+func leftAlignedHex(value string) ([]byte, error) {
+	if len(value)%2 != 0 {
+		value += "0"
+	}
+
+	return hex.DecodeString(value)
+}
@@ -0,0 +1,247 @@
+package ocra
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/otp"
+	"github.com/dadrus/oath/otp/mocks"
+)
+
+// test vectors come from RFC 6287 Appendix C
+
+const pinHash = "7110eda4d09e062aa5e4a390b0a572ac0d2c0220"
+
+func seeds(t *testing.T) ([]byte, []byte, []byte) {
+	t.Helper()
+
+	seed20, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(t, err)
+
+	seed32, err := hex.DecodeString("3132333435363738393031323334353637383930" +
+		"313233343536373839303132")
+	require.NoError(t, err)
+
+	seed64, err := hex.DecodeString("3132333435363738393031323334353637383930" +
+		"3132333435363738393031323334353637383930" +
+		"3132333435363738393031323334353637383930" +
+		"31323334")
+	require.NoError(t, err)
+
+	return seed20, seed32, seed64
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	seed20, seed32, seed64 := seeds(t)
+	timestamp := time.Unix(0x132d0b6*60, 0)
+
+	for _, tc := range []struct {
+		suite    string
+		key      []byte
+		input    Input
+		expected string
+	}{
+		// One-Way Challenge Response
+		{suite: "OCRA-1:HOTP-SHA1-6:QN08", key: seed20, input: Input{Question: "00000000"}, expected: "237653"},
+		{suite: "OCRA-1:HOTP-SHA1-6:QN08", key: seed20, input: Input{Question: "11111111"}, expected: "243178"},
+		{suite: "OCRA-1:HOTP-SHA1-6:QN08", key: seed20, input: Input{Question: "22222222"}, expected: "653583"},
+		{suite: "OCRA-1:HOTP-SHA1-6:QN08", key: seed20, input: Input{Question: "33333333"}, expected: "740991"},
+		{suite: "OCRA-1:HOTP-SHA1-6:QN08", key: seed20, input: Input{Question: "44444444"}, expected: "608993"},
+		{suite: "OCRA-1:HOTP-SHA1-6:QN08", key: seed20, input: Input{Question: "55555555"}, expected: "388898"},
+		{suite: "OCRA-1:HOTP-SHA1-6:QN08", key: seed20, input: Input{Question: "66666666"}, expected: "816933"},
+		{suite: "OCRA-1:HOTP-SHA1-6:QN08", key: seed20, input: Input{Question: "77777777"}, expected: "224598"},
+		{suite: "OCRA-1:HOTP-SHA1-6:QN08", key: seed20, input: Input{Question: "88888888"}, expected: "750600"},
+		{suite: "OCRA-1:HOTP-SHA1-6:QN08", key: seed20, input: Input{Question: "99999999"}, expected: "294470"},
+		{
+			suite: "OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1", key: seed32,
+			input: Input{Counter: 0, Question: "12345678", PIN: "1234"}, expected: "65347737",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1", key: seed32,
+			input: Input{Counter: 1, Question: "12345678", PINHash: mustDecodeHex(t, pinHash)}, expected: "86775851",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1", key: seed32,
+			input: Input{Counter: 9, Question: "12345678", PIN: "1234"}, expected: "08522129",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA256-8:QN08-PSHA1", key: seed32,
+			input: Input{Question: "00000000", PIN: "1234"}, expected: "83238735",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA256-8:QN08-PSHA1", key: seed32,
+			input: Input{Question: "44444444", PIN: "1234"}, expected: "86807031",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA512-8:C-QN08", key: seed64,
+			input: Input{Counter: 0, Question: "00000000"}, expected: "07016083",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA512-8:C-QN08", key: seed64,
+			input: Input{Counter: 9, Question: "99999999"}, expected: "31409299",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA512-8:QN08-T1M", key: seed64,
+			input: Input{Question: "00000000", Timestamp: timestamp}, expected: "95209754",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA512-8:QN08-T1M", key: seed64,
+			input: Input{Question: "44444444", Timestamp: timestamp}, expected: "36209546",
+		},
+		// Mutual Challenge-Response
+		{
+			suite: "OCRA-1:HOTP-SHA256-8:QA08", key: seed32,
+			input: Input{Question: "CLI22220", PeerQuestion: "SRV11110"}, expected: "28247970",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA256-8:QA08", key: seed32,
+			input: Input{Question: "SRV11110", PeerQuestion: "CLI22220"}, expected: "15510767",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA512-8:QA08", key: seed64,
+			input: Input{Question: "CLI22220", PeerQuestion: "SRV11110"}, expected: "79496648",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA512-8:QA08-PSHA1", key: seed64,
+			input: Input{Question: "SRV11110", PeerQuestion: "CLI22220", PIN: "1234"}, expected: "18806276",
+		},
+		// Plain Signature
+		{
+			suite: "OCRA-1:HOTP-SHA256-8:QA08", key: seed32,
+			input: Input{Question: "SIG10000"}, expected: "53095496",
+		},
+		{
+			suite: "OCRA-1:HOTP-SHA512-8:QA10-T1M", key: seed64,
+			input: Input{Question: "SIG1000000", Timestamp: timestamp}, expected: "77537423",
+		},
+	} {
+		t.Run(fmt.Sprintf("%s %s", tc.suite, tc.expected), func(t *testing.T) {
+			// GIVEN
+			alg, err := New(tc.key, tc.suite)
+			require.NoError(t, err)
+
+			// WHEN
+			value, err := alg.Generate(tc.input)
+
+			// THEN
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestGenerateWithInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	seed20, _, _ := seeds(t)
+
+	for _, tc := range []struct {
+		uc    string
+		suite string
+		input Input
+		err   error
+	}{
+		{uc: "empty question", suite: "OCRA-1:HOTP-SHA1-6:QN08", err: ErrInvalidQuestion},
+		{uc: "not numeric question", suite: "OCRA-1:HOTP-SHA1-6:QN08", input: Input{Question: "0x12"}, err: ErrInvalidQuestion},
+		{uc: "not hex question", suite: "OCRA-1:HOTP-SHA1-6:QH08", input: Input{Question: "XYZ"}, err: ErrInvalidQuestion},
+		{
+			uc: "question too long", suite: "OCRA-1:HOTP-SHA1-6:QN08",
+			input: Input{Question: "12345678901234567890"}, err: ErrInvalidQuestion,
+		},
+		{
+			uc: "peer question too long", suite: "OCRA-1:HOTP-SHA1-6:QA08",
+			input: Input{Question: "CLI22220", PeerQuestion: "SRV111100"}, err: ErrInvalidQuestion,
+		},
+		{uc: "missing timestamp", suite: "OCRA-1:HOTP-SHA512-8:QN08-T1M", input: Input{Question: "1234"}, err: ErrInvalidTimestamp},
+		{uc: "missing pin", suite: "OCRA-1:HOTP-SHA1-6:QN08-PSHA1", input: Input{Question: "1234"}, err: ErrInvalidPIN},
+		{
+			uc: "wrong pin hash size", suite: "OCRA-1:HOTP-SHA1-6:QN08-PSHA256",
+			input: Input{Question: "1234", PINHash: []byte{1, 2, 3}}, err: ErrInvalidPIN,
+		},
+		{
+			uc: "session too long", suite: "OCRA-1:HOTP-SHA1-6:QN08-S002",
+			input: Input{Question: "1234", Session: []byte{1, 2, 3}}, err: ErrInvalidSession,
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			alg, err := New(seed20, tc.suite)
+			require.NoError(t, err)
+
+			// WHEN
+			_, err = alg.Generate(tc.input)
+
+			// THEN
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	_, seed32, _ := seeds(t)
+
+	for _, tc := range []struct {
+		uc    string
+		value string
+		err   error
+	}{
+		{uc: "valid response", value: "65347737"},
+		{uc: "valid response with spaces", value: " 65347737 "},
+		{uc: "invalid response", value: "65347738", err: otp.ErrValidation},
+		{uc: "invalid length", value: "6534773", err: otp.ErrInvalidLength},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			alg, err := New(seed32, "OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1")
+			require.NoError(t, err)
+
+			// WHEN
+			err = alg.Validate(tc.value, Input{Counter: 0, Question: "12345678", PIN: "1234"})
+
+			// THEN
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	_, _, seed64 := seeds(t)
+
+	alg, err := New(seed64, "OCRA-1:HOTP-SHA512-8:QN08-T1M")
+	require.NoError(t, err)
+
+	exporter := mocks.NewExporterMock(t)
+	exporter.EXPECT().SetAlgorithm("ocra")
+	exporter.EXPECT().SetHashAlgorithm(otp.SHA512)
+	exporter.EXPECT().SetDigits(otp.Digits(8))
+	exporter.EXPECT().SetPeriod(time.Minute)
+	exporter.EXPECT().SetKey(seed64)
+
+	// WHEN -> expectations are met
+	alg.Export(exporter)
+}
+
+func mustDecodeHex(t *testing.T, value string) []byte {
+	t.Helper()
+
+	res, err := hex.DecodeString(value)
+	require.NoError(t, err)
+
+	return res
+}
@@ -0,0 +1,23 @@
+package ocra
+
+import (
+	"fmt"
+
+	"github.com/dadrus/oath/otp"
+)
+
+// Destroy overwrites the key with zeros. Afterwards, no responses can be generated anymore
+// (otp.ErrKeyDestroyed).
+func (a *Algorithm) Destroy() {
+	otp.Wipe(a.key)
+
+	a.destroyed = true
+}
+
+func (a Algorithm) String() string { return a.suite.String() }
+
+func (a Algorithm) GoString() string {
+	return fmt.Sprintf("ocra.Algorithm{key: %s, suite: %q}", otp.Redacted, a.suite.String())
+}
+
+func (a Algorithm) Format(f fmt.State, verb rune) { otp.FormatRedacted(f, verb, a) }
@@ -0,0 +1,31 @@
+package ocra
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/otp"
+)
+
+func TestAlgorithmDestroy(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	_, seed32, _ := seeds(t)
+
+	alg, err := New(seed32, "OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1")
+	require.NoError(t, err)
+
+	// WHEN
+	alg.Destroy()
+
+	// THEN
+	assert.Equal(t, make([]byte, len(seed32)), alg.Key())
+	assert.Equal(t, `ocra.Algorithm{key: [REDACTED], suite: "OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1"}`, fmt.Sprintf("%#v", alg))
+
+	err = alg.Validate("65347737", Input{Counter: 0, Question: "12345678", PIN: "1234"})
+	require.ErrorIs(t, err, otp.ErrKeyDestroyed)
+}
@@ -0,0 +1,273 @@
+package ocra
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dadrus/oath/otp"
+)
+
+var ErrInvalidSuite = errors.New("invalid ocra suite")
+
+// QuestionFormat defines the format of the challenge question, as specified in
+// the DataInput part of the OCRA suite
+type QuestionFormat byte
+
+const (
+	QuestionAlphanumeric = QuestionFormat('A')
+	QuestionNumeric      = QuestionFormat('N')
+	QuestionHex          = QuestionFormat('H')
+)
+
+// Suite represents a parsed OCRA suite string like OCRA-1:HOTP-SHA256-8:QN08-PSHA1-T1M
+// See https://www.rfc-editor.org/rfc/rfc6287#section-6 for details
+type Suite struct {
+	value          string
+	hashAlgorithm  otp.HashAlgorithm
+	digits         otp.Digits
+	counter        bool
+	questionFormat QuestionFormat
+	questionLength int
+	pinHash        otp.HashAlgorithm
+	sessionLength  int
+	timeStep       time.Duration
+}
+
+// ParseSuite parses the given OCRA suite string
+func ParseSuite(value string) (*Suite, error) {
+	const suiteParts = 3
+
+	parts := strings.Split(value, ":")
+	if len(parts) != suiteParts {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSuite, value)
+	}
+
+	if parts[0] != "OCRA-1" {
+		return nil, fmt.Errorf("%w: unsupported version %s", ErrInvalidSuite, parts[0])
+	}
+
+	suite := &Suite{value: value}
+
+	if err := suite.parseCryptoFunction(parts[1]); err != nil {
+		return nil, err
+	}
+
+	if err := suite.parseDataInput(parts[2]); err != nil {
+		return nil, err
+	}
+
+	return suite, nil
+}
+
+func (s *Suite) String() string { return s.value }
+
+// HashAlgorithm returns the hash algorithm used by the HMAC computation
+func (s *Suite) HashAlgorithm() otp.HashAlgorithm { return s.hashAlgorithm }
+
+// Digits returns the length of the response. 0 means, no truncation takes place
+func (s *Suite) Digits() otp.Digits { return s.digits }
+
+// Counter returns true if the suite makes use of a counter
+func (s *Suite) Counter() bool { return s.counter }
+
+// QuestionFormat returns the expected format of the challenge question
+func (s *Suite) QuestionFormat() QuestionFormat { return s.questionFormat }
+
+// QuestionLength returns the length of the challenge question, as defined in the suite
+func (s *Suite) QuestionLength() int { return s.questionLength }
+
+// PINHashAlgorithm returns the hash algorithm used to hash the PIN. Is empty if the
+// suite does not make use of a PIN
+func (s *Suite) PINHashAlgorithm() otp.HashAlgorithm { return s.pinHash }
+
+// SessionLength returns the length of the session information in bytes. Is 0 if the
+// suite does not make use of session information
+func (s *Suite) SessionLength() int { return s.sessionLength }
+
+// TimeStep returns the time step used for the timestamp. Is 0 if the suite does not
+// make use of timestamps
+func (s *Suite) TimeStep() time.Duration { return s.timeStep }
+
+func (s *Suite) parseCryptoFunction(value string) error {
+	const functionParts = 3
+
+	parts := strings.Split(value, "-")
+	if len(parts) != functionParts || parts[0] != "HOTP" {
+		return fmt.Errorf("%w: unsupported crypto function %s", ErrInvalidSuite, value)
+	}
+
+	algorithm, err := hashAlgorithm(parts[1])
+	if err != nil {
+		return err
+	}
+
+	digits, err := strconv.Atoi(parts[2])
+	if err != nil || (digits != 0 && (digits < 4 || digits > 10)) {
+		return fmt.Errorf("%w: unsupported truncation %s", ErrInvalidSuite, parts[2])
+	}
+
+	s.hashAlgorithm = algorithm
+	s.digits = otp.Digits(digits)
+
+	return nil
+}
+
+func (s *Suite) parseDataInput(value string) error {
+	parts := strings.Split(value, "-")
+
+	if parts[0] == "C" {
+		s.counter = true
+		parts = parts[1:]
+	}
+
+	if len(parts) == 0 {
+		return fmt.Errorf("%w: missing question in %s", ErrInvalidSuite, value)
+	}
+
+	if err := s.parseQuestion(parts[0]); err != nil {
+		return err
+	}
+
+	// the remaining parts are optional, but if present, they have to appear in the
+	// following order: P, S, T
+	parsers := []struct {
+		prefix byte
+		parse  func(value string) error
+	}{
+		{prefix: 'P', parse: s.parsePIN},
+		{prefix: 'S', parse: s.parseSession},
+		{prefix: 'T', parse: s.parseTimeStep},
+	}
+
+	for _, part := range parts[1:] {
+		for len(parsers) != 0 && len(part) != 0 && part[0] != parsers[0].prefix {
+			parsers = parsers[1:]
+		}
+
+		if len(parsers) == 0 || len(part) == 0 {
+			return fmt.Errorf("%w: unexpected data input %s", ErrInvalidSuite, part)
+		}
+
+		if err := parsers[0].parse(part[1:]); err != nil {
+			return err
+		}
+
+		parsers = parsers[1:]
+	}
+
+	return nil
+}
+
+func (s *Suite) parseQuestion(value string) error {
+	const (
+		questionPartLength = 4
+		minQuestionLength  = 4
+		maxQuestionLength  = 64
+	)
+
+	if len(value) != questionPartLength || value[0] != 'Q' {
+		return fmt.Errorf("%w: invalid question %s", ErrInvalidSuite, value)
+	}
+
+	format := QuestionFormat(value[1])
+	if format != QuestionAlphanumeric && format != QuestionNumeric && format != QuestionHex {
+		return fmt.Errorf("%w: unsupported question format %c", ErrInvalidSuite, value[1])
+	}
+
+	length, err := strconv.Atoi(value[2:])
+	if err != nil || length < minQuestionLength || length > maxQuestionLength {
+		return fmt.Errorf("%w: invalid question length %s", ErrInvalidSuite, value[2:])
+	}
+
+	s.questionFormat = format
+	s.questionLength = length
+
+	return nil
+}
+
+func (s *Suite) parsePIN(value string) error {
+	algorithm, err := hashAlgorithm(value)
+	if err != nil {
+		return err
+	}
+
+	s.pinHash = algorithm
+
+	return nil
+}
+
+func (s *Suite) parseSession(value string) error {
+	// rfc6287 defines S064 as default
+	const defaultSessionLength = 64
+
+	if len(value) == 0 {
+		s.sessionLength = defaultSessionLength
+
+		return nil
+	}
+
+	length, err := strconv.Atoi(value)
+	if err != nil || len(value) != 3 || length == 0 {
+		return fmt.Errorf("%w: invalid session information length %s", ErrInvalidSuite, value)
+	}
+
+	s.sessionLength = length
+
+	return nil
+}
+
+func (s *Suite) parseTimeStep(value string) error {
+	const (
+		maxSecondsOrMinutes = 59
+		maxHours            = 48
+	)
+
+	if len(value) < 2 {
+		return fmt.Errorf("%w: invalid time step %s", ErrInvalidSuite, value)
+	}
+
+	count, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || count < 1 {
+		return fmt.Errorf("%w: invalid time step %s", ErrInvalidSuite, value)
+	}
+
+	var (
+		unit     time.Duration
+		maxCount int
+	)
+
+	switch value[len(value)-1] {
+	case 'S':
+		unit, maxCount = time.Second, maxSecondsOrMinutes
+	case 'M':
+		unit, maxCount = time.Minute, maxSecondsOrMinutes
+	case 'H':
+		unit, maxCount = time.Hour, maxHours
+	default:
+		return fmt.Errorf("%w: invalid time step %s", ErrInvalidSuite, value)
+	}
+
+	if count > maxCount {
+		return fmt.Errorf("%w: invalid time step %s", ErrInvalidSuite, value)
+	}
+
+	s.timeStep = time.Duration(count) * unit
+
+	return nil
+}
+
+func hashAlgorithm(value string) (otp.HashAlgorithm, error) {
+	switch value {
+	case "SHA1":
+		return otp.SHA1, nil
+	case "SHA256":
+		return otp.SHA256, nil
+	case "SHA512":
+		return otp.SHA512, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported hash algorithm %s", ErrInvalidSuite, value)
+	}
+}
@@ -0,0 +1,88 @@
+package ocra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/otp"
+)
+
+func TestParseSuite(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		value string
+		exp   Suite
+		err   error
+	}{
+		{
+			value: "OCRA-1:HOTP-SHA1-6:QN08",
+			exp: Suite{
+				hashAlgorithm: otp.SHA1, digits: 6,
+				questionFormat: QuestionNumeric, questionLength: 8,
+			},
+		},
+		{
+			value: "OCRA-1:HOTP-SHA256-8:C-QN08-PSHA1",
+			exp: Suite{
+				hashAlgorithm: otp.SHA256, digits: 8, counter: true,
+				questionFormat: QuestionNumeric, questionLength: 8, pinHash: otp.SHA1,
+			},
+		},
+		{
+			value: "OCRA-1:HOTP-SHA512-8:QA10-T1M",
+			exp: Suite{
+				hashAlgorithm: otp.SHA512, digits: 8,
+				questionFormat: QuestionAlphanumeric, questionLength: 10, timeStep: time.Minute,
+			},
+		},
+		{
+			value: "OCRA-1:HOTP-SHA1-0:C-QH64-PSHA256-S128-T30S",
+			exp: Suite{
+				hashAlgorithm: otp.SHA1, digits: 0, counter: true,
+				questionFormat: QuestionHex, questionLength: 64, pinHash: otp.SHA256,
+				sessionLength: 128, timeStep: 30 * time.Second,
+			},
+		},
+		{
+			value: "OCRA-1:HOTP-SHA1-6:QN08-S-T48H",
+			exp: Suite{
+				hashAlgorithm: otp.SHA1, digits: 6,
+				questionFormat: QuestionNumeric, questionLength: 8,
+				sessionLength: 64, timeStep: 48 * time.Hour,
+			},
+		},
+		{value: "OCRA-1:HOTP-SHA1-6", err: ErrInvalidSuite},
+		{value: "OCRA-2:HOTP-SHA1-6:QN08", err: ErrInvalidSuite},
+		{value: "OCRA-1:TOTP-SHA1-6:QN08", err: ErrInvalidSuite},
+		{value: "OCRA-1:HOTP-MD5-6:QN08", err: ErrInvalidSuite},
+		{value: "OCRA-1:HOTP-SHA1-3:QN08", err: ErrInvalidSuite},
+		{value: "OCRA-1:HOTP-SHA1-11:QN08", err: ErrInvalidSuite},
+		{value: "OCRA-1:HOTP-SHA1-6:C", err: ErrInvalidSuite},
+		{value: "OCRA-1:HOTP-SHA1-6:QX08", err: ErrInvalidSuite},
+		{value: "OCRA-1:HOTP-SHA1-6:QN65", err: ErrInvalidSuite},
+		{value: "OCRA-1:HOTP-SHA1-6:QN08-T1M-PSHA1", err: ErrInvalidSuite},
+		{value: "OCRA-1:HOTP-SHA1-6:QN08-T60M", err: ErrInvalidSuite},
+		{value: "OCRA-1:HOTP-SHA1-6:QN08-T1D", err: ErrInvalidSuite},
+		{value: "OCRA-1:HOTP-SHA1-6:QN08-S12", err: ErrInvalidSuite},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			// WHEN
+			suite, err := ParseSuite(tc.value)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+
+				tc.exp.value = tc.value
+				assert.Equal(t, tc.exp, *suite)
+				assert.Equal(t, tc.value, suite.String())
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package oath
 
 import (
 	"bytes"
+	"io"
 	"time"
 
 	"github.com/dadrus/oath/otp"
@@ -66,3 +67,97 @@ func WithInitialSkew(skew int) Option {
 		o.InitialSkew = skew
 	}
 }
+
+// WithWorkWindow sets an asymmetric regular validity window for TOTP blobs with past
+// steps in the past and future steps in the future. It replaces WithWorkSkew. Unless
+// WithInitialWindow is used, future applies to the initial validity window as well.
+func WithWorkWindow(past, future int) Option {
+	return func(o *config) {
+		o.WorkSkew = past
+		o.WorkFutureSkew = &future
+	}
+}
+
+// WithInitialWindow sets an asymmetric initial validity window for TOTP blobs, used until
+// the first successful verification. It replaces WithInitialSkew.
+func WithInitialWindow(past, future int) Option {
+	return func(o *config) {
+		o.InitialSkew = past
+		o.InitialFutureSkew = &future
+	}
+}
+
+// WithSubject binds the blob to the given subject, e.g. an account id. The subject is not
+// stored in the blob, but authenticated while sealing it. A blob bound to a subject can only
+// be opened by providing the same subject to Verify and Export, which prevents copying
+// the blob of one user to another user. Blobs not bound to any subject are rejected with
+// ErrSubjectMismatch, unless WithUnboundBlobMigration is used.
+func WithSubject(subject string) Option {
+	return func(o *config) {
+		o.settings.subject = subject
+	}
+}
+
+// WithUnboundBlobMigration accepts blobs, which are not bound to any subject yet, even if a
+// subject is given via WithSubject. These are bound on the next successful verification.
+// Use it only while migrating existing blobs, as such blobs can be copied to other users.
+func WithUnboundBlobMigration() Option {
+	return func(o *config) {
+		o.settings.unboundBlobMigration = true
+	}
+}
+
+// WithKeyPolicy sets the policy keys are checked against by New. This applies to keys
+// provided via WithKey, as well as to generated and derived keys. Defaults to
+// otp.DefaultKeyPolicy. Keys of existing blobs are not checked.
+func WithKeyPolicy(policy otp.KeyPolicy) Option {
+	return func(o *config) {
+		o.settings.keyPolicy = &policy
+	}
+}
+
+// WithKeyLength sets the length in bytes of keys generated or derived by New. Defaults
+// to the output size of the hash algorithm, which fulfills otp.RecommendedKeyPolicy.
+// Derived keys must not be longer than 8160 bytes, otherwise New returns
+// ErrInvalidKeyLength.
+func WithKeyLength(length int) Option {
+	return func(o *config) {
+		if length > 0 {
+			o.settings.keyLength = length
+		}
+	}
+}
+
+// WithRandom sets the source of randomness used to generate keys and nonces, e.g. to make
+// use of the random number generator of an HSM. Defaults to crypto/rand. Errors reading
+// from it are returned as ErrKeyGeneration, respectively ErrNonceGeneration.
+func WithRandom(random io.Reader) Option {
+	return func(o *config) {
+		if random != nil {
+			o.settings.random = random
+		}
+	}
+}
+
+// WithClock sets the clock used to determine the current time. Defaults to the system clock.
+func WithClock(clock Clock) Option {
+	return func(o *config) {
+		if clock != nil {
+			o.settings.clock = clock
+		}
+	}
+}
+
+// settingsFrom returns the settings configured by the given options. Options, which
+// affect the blob configuration, are ignored.
+func settingsFrom(opts []Option) settings {
+	var cfg config
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	cfg.settings.lockout = cfg.Lockout
+
+	return cfg.settings
+}
@@ -2,9 +2,16 @@ package oath
 
 import (
 	"crypto/cipher"
-	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/dadrus/oath/otp"
 )
 
+// ErrKeyGeneration is returned by New if the key could not be generated, because reading
+// from the source of randomness failed.
+var ErrKeyGeneration = errors.New("key generation failed")
+
 type OTPType string
 
 const (
@@ -18,6 +25,7 @@ func (t OTPType) New(cipher cipher.AEAD, opts ...Option) (string, error) {
 	}
 
 	data := &config{Type: string(t)}
+	defer data.wipe()
 
 	for _, opt := range opts {
 		opt(data)
@@ -27,12 +35,73 @@ func (t OTPType) New(cipher cipher.AEAD, opts ...Option) (string, error) {
 		data.InitialSkew = data.WorkSkew
 	}
 
-	if len(data.Key) == 0 {
-		key := make([]byte, data.HashAlgorithm.Size())
-		rand.Read(key)
+	// a restricted look-ahead of the work window applies to the initial window as well,
+	// unless it has been configured explicitly
+	if data.InitialFutureSkew == nil && data.WorkFutureSkew != nil {
+		future := *data.WorkFutureSkew
+		data.InitialFutureSkew = &future
+	}
+
+	if future := data.workFutureSkew(); data.initialFutureSkew() < future {
+		data.InitialFutureSkew = &future
+	}
+
+	algorithm := data.HashAlgorithm
+	if len(algorithm) == 0 {
+		algorithm = otp.SHA1
+	} else if !algorithm.Supported() {
+		return "", fmt.Errorf("%w: %s", otp.ErrUnsupportedHashAlgorithm, algorithm)
+	}
+
+	length := data.settings.keyLength
+	if length == 0 {
+		length = algorithm.Size()
+	}
+
+	sources := 0
+	for _, set := range []bool{len(data.Key) != 0, data.KeyDerivation != nil, len(data.KeyReference) != 0} {
+		if set {
+			sources++
+		}
+	}
+
+	if sources > 1 {
+		return "", ErrConflictingKeyOptions
+	}
+
+	switch {
+	case len(data.KeyReference) != 0:
+		// the key is kept by the key provider and cannot be checked
+		return data.marshal(cipher)
+	case data.KeyDerivation != nil:
+		nonce, err := data.settings.randomBytes(keyDerivationNonceSize)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrKeyGeneration, err)
+		}
+
+		data.KeyDerivation.Nonce = nonce
+		data.KeyDerivation.Length = length
+
+		if err = data.deriveKey(); err != nil {
+			return "", err
+		}
+	case len(data.Key) == 0:
+		key, err := data.settings.randomBytes(length)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrKeyGeneration, err)
+		}
 
 		data.Key = key
 	}
 
+	policy := otp.DefaultKeyPolicy
+	if data.settings.keyPolicy != nil {
+		policy = *data.settings.keyPolicy
+	}
+
+	if err := policy.Check(data.Key); err != nil {
+		return "", err
+	}
+
 	return data.marshal(cipher)
 }
@@ -1,5 +1,7 @@
 package otp
 
+import "context"
+
 type ValidationOption func(current int64) SkewIterator
 
 //go:generate mockery --name Algorithm --structname AlgorithmMock
@@ -10,7 +12,12 @@ type Algorithm interface {
 
 	// Validate validates the given otp value for the given reference
 	// opts can optionally be used to provide algorithm specific validation options
-	Validate(value string, reference int64, opts ...ValidationOption) (int64, error)
+	// On success the details about the matched value are returned
+	Validate(value string, reference int64, opts ...ValidationOption) (Result, error)
+
+	// ValidateContext works like Validate, but aborts the validation with ErrCanceled
+	// if the given context is done
+	ValidateContext(ctx context.Context, value string, reference int64, opts ...ValidationOption) (Result, error)
 
 	// Export exports the configuration of the algorithm
 	Export(exporter Exporter)
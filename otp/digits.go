@@ -1,13 +1,29 @@
 package otp
 
-import "fmt"
+import "strconv"
 
 type Digits int
 
 func (d Digits) Format(value int32) string {
-	return fmt.Sprintf(fmt.Sprintf("%%0%dd", d), value)
+	var buf [16]byte
+
+	return string(d.AppendFormat(buf[:0], value))
+}
+
+// AppendFormat appends the value, padded with leading zeros to the amount of digits, to dst
+// and returns the extended buffer.
+func (d Digits) AppendFormat(dst []byte, value int32) []byte {
+	var buf [16]byte
+
+	formatted := strconv.AppendInt(buf[:0], int64(value), 10) //nolint:gomnd
+
+	for i := len(formatted); i < d.Length(); i++ {
+		dst = append(dst, '0')
+	}
+
+	return append(dst, formatted...)
 }
 
 func (d Digits) Length() int { return int(d) }
 
-func (d Digits) String() string { return fmt.Sprintf("%d", d) }
+func (d Digits) String() string { return strconv.Itoa(int(d)) }
@@ -0,0 +1,34 @@
+package otp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigitsFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		digits Digits
+		value  int32
+		exp    string
+	}{
+		{digits: 6, value: 0, exp: "000000"},
+		{digits: 6, value: 42, exp: "000042"},
+		{digits: 6, value: 755224, exp: "755224"},
+		{digits: 8, value: 1234, exp: "00001234"},
+		{digits: 10, value: 2147483647, exp: "2147483647"},
+		{digits: 2, value: 1234, exp: "1234"},
+	} {
+		t.Run(tc.exp, func(t *testing.T) {
+			// WHEN
+			formatted := tc.digits.Format(tc.value)
+			appended := tc.digits.AppendFormat([]byte("foo"), tc.value)
+
+			// THEN
+			assert.Equal(t, tc.exp, formatted)
+			assert.Equal(t, "foo"+tc.exp, string(appended))
+		})
+	}
+}
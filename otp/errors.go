@@ -1,8 +1,32 @@
 package otp
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrInvalidLength = errors.New("invalid length")
-	ErrValidation    = errors.New("otp invalid")
+	// ErrValidation is returned if the otp value is not valid. All other validation
+	// errors below, except ErrMalformed and ErrInvalidLength, match it as well.
+	ErrValidation = errors.New("otp invalid")
+	// ErrReplayed is returned if the otp value has already been used, or is outdated by
+	// a newer one, which has already been used.
+	ErrReplayed = fmt.Errorf("%w: already used", ErrValidation)
+	// ErrOutOfWindow is returned if the otp value would be valid, but not within the
+	// current validity window.
+	ErrOutOfWindow = fmt.Errorf("%w: outside of the validity window", ErrValidation)
+
+	// ErrMalformed is returned if the otp value does not have the expected format.
+	ErrMalformed = errors.New("otp malformed")
+	// ErrInvalidLength is returned if the otp value does not have the expected length.
+	// It matches ErrMalformed.
+	ErrInvalidLength = fmt.Errorf("%w: invalid length", ErrMalformed)
+	// ErrInvalidCharacter is returned if the otp value contains a character, which is not
+	// a digit. It matches ErrMalformed and, for backwards compatibility, ErrValidation.
+	ErrInvalidCharacter = fmt.Errorf("%w: %w: invalid character", ErrMalformed, ErrValidation)
+
+	// ErrCanceled is returned if the validation has been aborted, because the context
+	// has been canceled, or its deadline has been exceeded. The returned error matches
+	// the error of the context (context.Canceled or context.DeadlineExceeded) as well.
+	ErrCanceled = errors.New("otp validation canceled")
 )
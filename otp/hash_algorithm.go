@@ -1,12 +1,20 @@
 package otp
 
 import (
-	"crypto/sha1"
-	"crypto/sha256"
-	"crypto/sha512"
+	"crypto"
+	_ "crypto/sha1"   // register the hash function
+	_ "crypto/sha256" // register the hash function
+	_ "crypto/sha512" // register the hash function
+	"errors"
+	"fmt"
 	"hash"
+	"strings"
+	"sync"
 )
 
+// ErrUnsupportedHashAlgorithm is returned if a hash algorithm is not registered
+var ErrUnsupportedHashAlgorithm = errors.New("unsupported hash algorithm")
+
 type HashAlgorithm string
 
 const (
@@ -15,32 +23,88 @@ const (
 	SHA512 = HashAlgorithm("SHA512")
 )
 
+//nolint:gochecknoglobals
+var registry = struct {
+	mut    sync.RWMutex
+	hashes map[HashAlgorithm]crypto.Hash
+}{
+	hashes: map[HashAlgorithm]crypto.Hash{
+		SHA1:   crypto.SHA1,
+		SHA256: crypto.SHA256,
+		SHA512: crypto.SHA512,
+	},
+}
+
+// RegisterHashAlgorithm makes the given hash function available under the given name, e.g.
+//
+//	otp.RegisterHashAlgorithm("SHA3-256", crypto.SHA3_256)
+//
+// Names are case-insensitive and stored in upper case. The implementation of the hash function
+// must be linked into the binary, e.g. by importing golang.org/x/crypto/sha3. Already registered
+// names can be re-registered.
+func RegisterHashAlgorithm(name string, hash crypto.Hash) (HashAlgorithm, error) {
+	algorithm := HashAlgorithm(strings.ToUpper(strings.TrimSpace(name)))
+	if len(algorithm) == 0 {
+		return "", fmt.Errorf("%w: empty name", ErrUnsupportedHashAlgorithm)
+	}
+
+	if !hash.Available() {
+		return "", fmt.Errorf("%w: %s is not linked into the binary", ErrUnsupportedHashAlgorithm, name)
+	}
+
+	registry.mut.Lock()
+	defer registry.mut.Unlock()
+
+	registry.hashes[algorithm] = hash
+
+	return algorithm, nil
+}
+
+// ParseHashAlgorithm returns the registered hash algorithm with the given (case-insensitive)
+// name, or ErrUnsupportedHashAlgorithm.
+func ParseHashAlgorithm(name string) (HashAlgorithm, error) {
+	algorithm := HashAlgorithm(strings.ToUpper(strings.TrimSpace(name)))
+	if !algorithm.Supported() {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedHashAlgorithm, name)
+	}
+
+	return algorithm, nil
+}
+
+// Supported tells whether the hash algorithm has been registered
+func (h HashAlgorithm) Supported() bool {
+	_, ok := h.lookup()
+
+	return ok
+}
+
 func (h HashAlgorithm) String() string {
 	return string(h)
 }
 
+// Size returns the size of the hash in bytes, or 0 if the hash algorithm is not supported
 func (h HashAlgorithm) Size() int {
-	switch h {
-	case SHA1:
-		return sha1.Size //nolint:gosec
-	case SHA256:
-		return sha256.Size
-	case SHA512:
-		return sha512.Size
-	default:
-		panic("unsupported hash algorithm")
+	if hash, ok := h.lookup(); ok {
+		return hash.Size()
 	}
+
+	return 0
 }
 
+// Hash returns a new hash.Hash, or nil if the hash algorithm is not supported
 func (h HashAlgorithm) Hash() hash.Hash {
-	switch h {
-	case SHA1:
-		return sha1.New() //nolint:gosec
-	case SHA256:
-		return sha256.New()
-	case SHA512:
-		return sha512.New()
-	default:
-		panic("unsupported hash algorithm")
+	if hash, ok := h.lookup(); ok {
+		return hash.New()
 	}
+
+	return nil
+}
+
+func (h HashAlgorithm) lookup() (crypto.Hash, bool) {
+	registry.mut.RLock()
+	defer registry.mut.RUnlock()
+
+	hash, ok := registry.hashes[h]
+
+	return hash, ok
 }
@@ -0,0 +1,67 @@
+package otp
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		algorithm HashAlgorithm
+		size      int
+	}{
+		{algorithm: SHA1, size: 20},
+		{algorithm: SHA256, size: 32},
+		{algorithm: SHA512, size: 64},
+		{algorithm: HashAlgorithm("MD4"), size: 0},
+		{algorithm: HashAlgorithm(""), size: 0},
+	} {
+		t.Run(tc.algorithm.String(), func(t *testing.T) {
+			// WHEN
+			size := tc.algorithm.Size()
+			hash := tc.algorithm.Hash()
+
+			// THEN
+			assert.Equal(t, tc.size, size)
+			assert.Equal(t, tc.size != 0, tc.algorithm.Supported())
+
+			if tc.size != 0 {
+				require.NotNil(t, hash)
+				assert.Equal(t, tc.size, hash.Size())
+			} else {
+				assert.Nil(t, hash)
+			}
+		})
+	}
+}
+
+func TestRegisterHashAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	algorithm, err := RegisterHashAlgorithm("sha-384", crypto.SHA384)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, HashAlgorithm("SHA-384"), algorithm)
+	assert.Equal(t, 48, algorithm.Size())
+
+	parsed, err := ParseHashAlgorithm("Sha-384")
+	require.NoError(t, err)
+	assert.Equal(t, algorithm, parsed)
+
+	// hash functions not linked into the binary, as well as empty names are rejected
+	_, err = RegisterHashAlgorithm("MD4", crypto.MD4)
+	require.ErrorIs(t, err, ErrUnsupportedHashAlgorithm)
+
+	_, err = RegisterHashAlgorithm(" ", crypto.SHA384)
+	require.ErrorIs(t, err, ErrUnsupportedHashAlgorithm)
+
+	_, err = ParseHashAlgorithm("MD4")
+	require.ErrorIs(t, err, ErrUnsupportedHashAlgorithm)
+}
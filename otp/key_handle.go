@@ -0,0 +1,58 @@
+package otp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMACFailed is returned if a KeyHandle failed to calculate the HMAC.
+	ErrMACFailed = errors.New("mac calculation failed")
+	// ErrKeyDestroyed is returned if the key has been destroyed. It matches ErrMACFailed.
+	ErrKeyDestroyed = fmt.Errorf("%w: key destroyed", ErrMACFailed)
+)
+
+// KeyHandle calculates the HMAC otp values are derived from. The key might never leave
+// the backend implementing it, like a PKCS#11 token or an HSM.
+type KeyHandle interface {
+	// MAC appends the HMAC of message, calculated with the given hash algorithm, to dst
+	// and returns the extended buffer.
+	MAC(dst []byte, algorithm HashAlgorithm, message []byte) ([]byte, error)
+}
+
+// ExportableKey is implemented by key handles, which are able to reveal the key, e.g. to
+// export it to an authenticator.
+type ExportableKey interface {
+	Key() []byte
+}
+
+// MemoryKey is the default KeyHandle, which holds the key in memory. It is never printed
+// by the fmt package.
+type MemoryKey []byte
+
+func (k MemoryKey) MAC(dst []byte, algorithm HashAlgorithm, message []byte) ([]byte, error) {
+	mac := hmac.New(algorithm.Hash, k)
+	mac.Write(message)
+
+	return mac.Sum(dst), nil
+}
+
+func (k MemoryKey) Key() []byte { return bytes.Clone(k) }
+
+// Destroy overwrites the key with zeros.
+func (k MemoryKey) Destroy() { Wipe(k) }
+
+func (k MemoryKey) String() string { return Redacted }
+
+func (k MemoryKey) GoString() string { return Redacted }
+
+func (k MemoryKey) Format(f fmt.State, verb rune) { FormatRedacted(f, verb, k) }
+
+// Wipe overwrites the given buffer with zeros, e.g. to remove key material from memory.
+func Wipe(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+}
@@ -0,0 +1,77 @@
+package otp
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// MinKeyLength is the minimum key length in bytes (128 bits) required by RFC 4226.
+	MinKeyLength = 16
+	// RecommendedKeyLength is the key length in bytes (160 bits) recommended by RFC 4226.
+	RecommendedKeyLength = 20
+)
+
+var (
+	// ErrWeakKey is returned if a key does not fulfill the configured KeyPolicy. All
+	// other key errors below match it as well.
+	ErrWeakKey = errors.New("weak key")
+	// ErrKeyTooShort is returned if a key is shorter than required by the KeyPolicy.
+	ErrKeyTooShort = fmt.Errorf("%w: too short", ErrWeakKey)
+	// ErrLowEntropyKey is returned if a key has obviously low entropy, like a key
+	// consisting of a single repeated byte, e.g. all zeros.
+	ErrLowEntropyKey = fmt.Errorf("%w: low entropy", ErrWeakKey)
+)
+
+var (
+	// DefaultKeyPolicy enforces the minimum key length required by RFC 4226 and rejects
+	// low entropy keys.
+	DefaultKeyPolicy = KeyPolicy{MinLength: MinKeyLength}
+	// RecommendedKeyPolicy enforces the key length recommended by RFC 4226 and rejects
+	// low entropy keys.
+	RecommendedKeyPolicy = KeyPolicy{MinLength: RecommendedKeyLength}
+	// LaxKeyPolicy accepts any non-empty key. Use it only for keys, which cannot be
+	// replaced, like keys of already enrolled tokens.
+	LaxKeyPolicy = KeyPolicy{AllowLowEntropy: true}
+)
+
+// KeyPolicy defines the requirements a key has to fulfill. Empty keys are never accepted.
+type KeyPolicy struct {
+	// MinLength is the minimum length of the key in bytes.
+	MinLength int
+	// AllowLowEntropy disables the check for keys consisting of a single repeated byte.
+	AllowLowEntropy bool
+}
+
+// Check returns ErrKeyTooShort or ErrLowEntropyKey if the given key does not fulfill the
+// policy.
+func (p KeyPolicy) Check(key []byte) error {
+	if len(key) == 0 || len(key) < p.MinLength {
+		return fmt.Errorf("%w: %d bits, at least %d bits required",
+			ErrKeyTooShort, len(key)*8, p.minLength()*8) //nolint:gomnd
+	}
+
+	if !p.AllowLowEntropy && repeated(key) {
+		return ErrLowEntropyKey
+	}
+
+	return nil
+}
+
+func (p KeyPolicy) minLength() int {
+	if p.MinLength < 1 {
+		return 1
+	}
+
+	return p.MinLength
+}
+
+func repeated(key []byte) bool {
+	for _, b := range key[1:] {
+		if b != key[0] {
+			return false
+		}
+	}
+
+	return true
+}
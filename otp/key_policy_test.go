@@ -0,0 +1,46 @@
+package otp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyPolicyCheck(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		policy KeyPolicy
+		key    []byte
+		err    error
+	}{
+		{uc: "empty key", policy: LaxKeyPolicy, err: ErrKeyTooShort},
+		{uc: "short key", policy: DefaultKeyPolicy, key: []byte("123456789012345"), err: ErrKeyTooShort},
+		{uc: "128 bit key", policy: DefaultKeyPolicy, key: []byte("1234567890123456")},
+		{uc: "128 bit key with recommended policy", policy: RecommendedKeyPolicy, key: []byte("1234567890123456"), err: ErrKeyTooShort},
+		{uc: "160 bit key with recommended policy", policy: RecommendedKeyPolicy, key: []byte("12345678901234567890")},
+		{uc: "all zero key", policy: DefaultKeyPolicy, key: make([]byte, 20), err: ErrLowEntropyKey},
+		{uc: "repeated byte key", policy: DefaultKeyPolicy, key: bytes.Repeat([]byte{0xab}, 20), err: ErrLowEntropyKey},
+		{uc: "repeated byte key with lax policy", policy: LaxKeyPolicy, key: bytes.Repeat([]byte{0xab}, 20)},
+		{uc: "short key with lax policy", policy: LaxKeyPolicy, key: []byte("1")},
+	} {
+		tc := tc
+
+		t.Run(tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			err := tc.policy.Check(tc.key)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				require.ErrorIs(t, err, ErrWeakKey)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
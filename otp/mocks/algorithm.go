@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	otp "github.com/dadrus/oath/otp"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -96,7 +98,7 @@ func (_c *AlgorithmMock_Generate_Call) RunAndReturn(run func(int64) string) *Alg
 }
 
 // Validate provides a mock function with given fields: value, reference, opts
-func (_m *AlgorithmMock) Validate(value string, reference int64, opts ...otp.ValidationOption) (int64, error) {
+func (_m *AlgorithmMock) Validate(value string, reference int64, opts ...otp.ValidationOption) (otp.Result, error) {
 	_va := make([]interface{}, len(opts))
 	for _i := range opts {
 		_va[_i] = opts[_i]
@@ -106,15 +108,15 @@ func (_m *AlgorithmMock) Validate(value string, reference int64, opts ...otp.Val
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
-	var r0 int64
+	var r0 otp.Result
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, int64, ...otp.ValidationOption) (int64, error)); ok {
+	if rf, ok := ret.Get(0).(func(string, int64, ...otp.ValidationOption) (otp.Result, error)); ok {
 		return rf(value, reference, opts...)
 	}
-	if rf, ok := ret.Get(0).(func(string, int64, ...otp.ValidationOption) int64); ok {
+	if rf, ok := ret.Get(0).(func(string, int64, ...otp.ValidationOption) otp.Result); ok {
 		r0 = rf(value, reference, opts...)
 	} else {
-		r0 = ret.Get(0).(int64)
+		r0 = ret.Get(0).(otp.Result)
 	}
 
 	if rf, ok := ret.Get(1).(func(string, int64, ...otp.ValidationOption) error); ok {
@@ -153,12 +155,81 @@ func (_c *AlgorithmMock_Validate_Call) Run(run func(value string, reference int6
 	return _c
 }
 
-func (_c *AlgorithmMock_Validate_Call) Return(_a0 int64, _a1 error) *AlgorithmMock_Validate_Call {
+func (_c *AlgorithmMock_Validate_Call) Return(_a0 otp.Result, _a1 error) *AlgorithmMock_Validate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *AlgorithmMock_Validate_Call) RunAndReturn(run func(string, int64, ...otp.ValidationOption) (otp.Result, error)) *AlgorithmMock_Validate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ValidateContext provides a mock function with given fields: ctx, value, reference, opts
+func (_m *AlgorithmMock) ValidateContext(ctx context.Context, value string, reference int64, opts ...otp.ValidationOption) (otp.Result, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, value, reference)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 otp.Result
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, ...otp.ValidationOption) (otp.Result, error)); ok {
+		return rf(ctx, value, reference, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, ...otp.ValidationOption) otp.Result); ok {
+		r0 = rf(ctx, value, reference, opts...)
+	} else {
+		r0 = ret.Get(0).(otp.Result)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, ...otp.ValidationOption) error); ok {
+		r1 = rf(ctx, value, reference, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AlgorithmMock_ValidateContext_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateContext'
+type AlgorithmMock_ValidateContext_Call struct {
+	*mock.Call
+}
+
+// ValidateContext is a helper method to define mock.On call
+//   - ctx context.Context
+//   - value string
+//   - reference int64
+//   - opts ...otp.ValidationOption
+func (_e *AlgorithmMock_Expecter) ValidateContext(ctx interface{}, value interface{}, reference interface{}, opts ...interface{}) *AlgorithmMock_ValidateContext_Call {
+	return &AlgorithmMock_ValidateContext_Call{Call: _e.mock.On("ValidateContext",
+		append([]interface{}{ctx, value, reference}, opts...)...)}
+}
+
+func (_c *AlgorithmMock_ValidateContext_Call) Run(run func(ctx context.Context, value string, reference int64, opts ...otp.ValidationOption)) *AlgorithmMock_ValidateContext_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]otp.ValidationOption, len(args)-3)
+		for i, a := range args[3:] {
+			if a != nil {
+				variadicArgs[i] = a.(otp.ValidationOption)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *AlgorithmMock_ValidateContext_Call) Return(_a0 otp.Result, _a1 error) *AlgorithmMock_ValidateContext_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *AlgorithmMock_Validate_Call) RunAndReturn(run func(string, int64, ...otp.ValidationOption) (int64, error)) *AlgorithmMock_Validate_Call {
+func (_c *AlgorithmMock_ValidateContext_Call) RunAndReturn(run func(context.Context, string, int64, ...otp.ValidationOption) (otp.Result, error)) *AlgorithmMock_ValidateContext_Call {
 	_c.Call.Return(run)
 	return _c
 }
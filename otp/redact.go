@@ -0,0 +1,29 @@
+package otp
+
+import (
+	"fmt"
+	"io"
+)
+
+// Redacted is printed instead of key material.
+const Redacted = "[REDACTED]"
+
+// Redactable is implemented by types holding key material. Neither String nor GoString
+// must reveal it.
+type Redactable interface {
+	fmt.Stringer
+	fmt.GoStringer
+}
+
+// FormatRedacted writes the GoString representation of value to f for %#v and the String
+// representation for all other verbs. Types holding key material use it to implement
+// fmt.Formatter, so that no verb, like %x or %+v, prints the raw fields.
+func FormatRedacted(f fmt.State, verb rune, value Redactable) {
+	if verb == 'v' && f.Flag('#') {
+		io.WriteString(f, value.GoString()) //nolint:errcheck
+
+		return
+	}
+
+	io.WriteString(f, value.String()) //nolint:errcheck
+}
@@ -0,0 +1,37 @@
+package otp
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type secret struct{ key []byte }
+
+func (s secret) String() string { return "secret" }
+
+func (s secret) GoString() string { return "otp.secret{key: " + Redacted + "}" }
+
+func (s secret) Format(f fmt.State, verb rune) { FormatRedacted(f, verb, s) }
+
+func TestFormatRedacted(t *testing.T) {
+	t.Parallel()
+
+	value := secret{key: []byte("12345678901234567890")}
+
+	for format, expected := range map[string]string{
+		"%v":  "secret",
+		"%+v": "secret",
+		"%s":  "secret",
+		"%x":  "secret",
+		"%q":  "secret",
+		"%d":  "secret",
+		"%#v": "otp.secret{key: [REDACTED]}",
+	} {
+		assert.Equal(t, expected, fmt.Sprintf(format, value), format)
+		assert.Equal(t, expected, fmt.Sprintf(format, &value), format)
+	}
+
+	assert.Equal(t, Redacted, fmt.Sprintf("%x", MemoryKey("12345678901234567890")))
+}
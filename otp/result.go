@@ -0,0 +1,31 @@
+package otp
+
+import "time"
+
+// Window is the range of steps (counters for HOTP, time steps for TOTP) searched
+// while validating an otp value. Both boundaries are inclusive.
+type Window struct {
+	First int64
+	Last  int64
+}
+
+// Result holds the details of a successful validation
+type Result struct {
+	// Step is the counter (HOTP), respectively the time step (TOTP) the otp value matched
+	Step int64
+
+	// Deviation is the signed difference in steps between the matched step and the step
+	// derived from the reference used for validation
+	Deviation int64
+
+	// Drift is the Deviation expressed as time.Duration. It is always 0 for HOTP
+	Drift time.Duration
+
+	// Window is the range of steps, which has been searched
+	Window Window
+
+	// ValidFor is the time, the matched otp value remains valid, measured from the reference
+	// used for validation without taking the validity window into account. It is 0 for HOTP,
+	// as well as for values from past time steps
+	ValidFor time.Duration
+}
@@ -0,0 +1,156 @@
+package oath
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/oathtest"
+	"github.com/dadrus/oath/otp"
+	"github.com/dadrus/oath/totp"
+)
+
+func TestNewWithHashAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	c := newAEAD(t)
+
+	algorithm, err := otp.RegisterHashAlgorithm("SHA-384", crypto.SHA384)
+	require.NoError(t, err)
+
+	// WHEN
+	blob, err := TOTP.New(c, WithHashAlgorithm(algorithm))
+
+	// THEN
+	require.NoError(t, err)
+
+	clock := oathtest.NewClock(time.Unix(1111111109, 0))
+
+	tok, err := Open(blob, c, WithClock(clock))
+	require.NoError(t, err)
+	assert.Equal(t, algorithm, tok.Metadata().HashAlgorithm)
+	assert.Len(t, tok.data.Key, 48)
+
+	value := newTOTP(t, tok.data.Key, totp.WithHashAlgorithm(algorithm)).Generate(1111111109)
+	require.NoError(t, tok.Verify(value))
+
+	// WHEN
+	_, err = TOTP.New(c, WithHashAlgorithm("MD5"))
+
+	// THEN
+	require.ErrorIs(t, err, otp.ErrUnsupportedHashAlgorithm)
+}
+
+func TestNewWithKeyPolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc   string
+		opts []Option
+		err  error
+	}{
+		{uc: "generated key"},
+		{uc: "generated key with recommended policy", opts: []Option{WithKeyPolicy(otp.RecommendedKeyPolicy)}},
+		{uc: "short generated key", opts: []Option{WithKeyLength(10)}, err: otp.ErrKeyTooShort},
+		{uc: "short key", opts: []Option{WithKey([]byte("1234567890"))}, err: otp.ErrKeyTooShort},
+		{uc: "all zero key", opts: []Option{WithKey(make([]byte, 20))}, err: otp.ErrLowEntropyKey},
+		{
+			uc:   "128 bit key with recommended policy",
+			opts: []Option{WithKey([]byte("1234567890123456")), WithKeyPolicy(otp.RecommendedKeyPolicy)},
+			err:  otp.ErrKeyTooShort,
+		},
+		{uc: "short key with lax policy", opts: []Option{WithKey([]byte("1234567890")), WithKeyPolicy(otp.LaxKeyPolicy)}},
+		{
+			uc:   "short derived key",
+			opts: []Option{WithKeyDerivation("alice"), WithMasterSecret([]byte("foo")), WithKeyLength(10)},
+			err:  otp.ErrKeyTooShort,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			c := newAEAD(t)
+
+			// WHEN
+			blob, err := HOTP.New(c, tc.opts...)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			tok, err := Open(blob, c)
+			require.NoError(t, err)
+
+			_, key, err := tok.Export("foo", "bar")
+			require.NoError(t, err)
+			assert.NotEmpty(t, key)
+		})
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("rng failure") }
+
+func TestNewWithRandom(t *testing.T) {
+	t.Parallel()
+
+	entropy := make([]byte, 64)
+	for i := range entropy {
+		entropy[i] = byte(i)
+	}
+
+	c := newAEAD(t)
+
+	for _, tc := range []struct {
+		uc     string
+		random io.Reader
+		err    error
+	}{
+		{uc: "deterministic source", random: bytes.NewReader(entropy)},
+		{uc: "failing source", random: failingReader{}, err: ErrKeyGeneration},
+		{uc: "source exhausted after key generation", random: bytes.NewReader(entropy[:20]), err: ErrNonceGeneration},
+	} {
+		tc := tc
+
+		t.Run(tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			blob, err := HOTP.New(c, WithRandom(tc.random))
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			// the same entropy results in the same blob
+			again, err := HOTP.New(c, WithRandom(bytes.NewReader(entropy)))
+			require.NoError(t, err)
+			assert.Equal(t, blob, again)
+
+			_, key, err := Export(blob, c, "foo", "bar")
+			require.NoError(t, err)
+			assert.Equal(t, "AAAQEAYEAUDAOCAJBIFQYDIOB4IBCEQT", key)
+		})
+	}
+}
@@ -19,7 +19,7 @@ var (
 	ErrUnsupportedURIScheme     = errors.New("unsupported uri scheme")
 	ErrUnsupportedOTPAlgorithm  = errors.New("unsupported otp algorithm")
 	ErrInvalidSecretEncoding    = errors.New("invalid secret encoding")
-	ErrUnsupportedHashAlgorithm = errors.New("unsupported hash algorithm")
+	ErrUnsupportedHashAlgorithm = otp.ErrUnsupportedHashAlgorithm
 	ErrNoCounterPresent         = errors.New("no counter present")
 )
 
@@ -48,7 +48,9 @@ func FromURI(value string) (*AlgorithmParameters, error) {
 		return nil, ErrUnsupportedURIScheme
 	}
 
-	if uri.Scheme != "otpauth" {
+	if uri.Scheme == MigrationScheme {
+		return nil, fmt.Errorf("%w: %s, use FromMigrationURI instead", ErrUnsupportedURIScheme, uri.Scheme)
+	} else if uri.Scheme != "otpauth" {
 		return nil, fmt.Errorf("%w: %s", ErrUnsupportedURIScheme, uri.Scheme)
 	}
 
@@ -91,18 +93,36 @@ func FromURI(value string) (*AlgorithmParameters, error) {
 	}, nil
 }
 
-func (d *AlgorithmParameters) Algorithm() otp.Algorithm {
+// Algorithm creates the algorithm described by the parameters. As imported keys cannot
+// be replaced, the key is checked against otp.LaxKeyPolicy only.
+func (d *AlgorithmParameters) Algorithm() (otp.Algorithm, error) {
+	var (
+		alg otp.Algorithm
+		err error
+	)
+
 	if d.otpType == "totp" {
-		return totp.New(
+		alg, err = totp.New(
 			d.key,
 			totp.WithDigits(d.digits),
 			totp.WithTimeStep(d.period),
 			totp.WithHashAlgorithm(otp.HashAlgorithm(d.hashAlgorithm)),
 			totp.WithT0(0),
+			totp.WithKeyPolicy(otp.LaxKeyPolicy),
+		)
+	} else {
+		alg, err = hotp.New(d.key,
+			hotp.WithDigits(d.digits),
+			hotp.WithHashAlgorithm(otp.HashAlgorithm(d.hashAlgorithm)),
+			hotp.WithKeyPolicy(otp.LaxKeyPolicy),
 		)
 	}
 
-	return hotp.New(d.key, hotp.WithDigits(d.digits))
+	if err != nil {
+		return nil, err
+	}
+
+	return alg, nil
 }
 
 func (d *AlgorithmParameters) Key() []byte { return bytes.Clone(d.key) }
@@ -176,15 +196,17 @@ func extractPeriod(uri *url.URL) time.Duration {
 }
 
 func extractHashAlgorithm(uri *url.URL) (string, error) {
-	algorithm := strings.ToUpper(uri.Query().Get("algorithm"))
-	switch algorithm {
-	case "SHA1", "SHA256", "SHA512":
-		return algorithm, nil
-	case "":
-		return "SHA1", nil
-	default:
-		return "", ErrUnsupportedHashAlgorithm
+	name := uri.Query().Get("algorithm")
+	if len(name) == 0 {
+		return otp.SHA1.String(), nil
+	}
+
+	algorithm, err := otp.ParseHashAlgorithm(name)
+	if err != nil {
+		return "", err
 	}
+
+	return algorithm.String(), nil
 }
 
 func extractDigits(uri *url.URL) otp.Digits {
@@ -1,6 +1,7 @@
 package otpauth
 
 import (
+	"crypto"
 	"encoding/hex"
 	"testing"
 	"time"
@@ -255,7 +256,17 @@ func TestDecodedOTPAlgorithm(t *testing.T) {
 		},
 		{
 			uc:      "hotp",
-			vector:  "otpauth://hotp/FooBar:foo@bar.com?issuer=FooBar&secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ",
+			vector:  "otpauth://hotp/FooBar:foo@bar.com?counter=0&issuer=FooBar&secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ",
+			otpType: &hotp.Algorithm{},
+		},
+		{
+			uc:      "totp with 80 bit secret",
+			vector:  "otpauth://totp/FooBar:foo@bar.com?issuer=FooBar&secret=JBSWY3DPEHPK3PXP",
+			otpType: &totp.Algorithm{},
+		},
+		{
+			uc:      "hotp with 80 bit secret",
+			vector:  "otpauth://hotp/FooBar:foo@bar.com?counter=0&issuer=FooBar&secret=JBSWY3DPEHPK3PXP",
 			otpType: &hotp.Algorithm{},
 		},
 	} {
@@ -263,7 +274,8 @@ func TestDecodedOTPAlgorithm(t *testing.T) {
 			dec, err := FromURI(tc.vector)
 			require.NoError(t, err)
 
-			alg := dec.Algorithm()
+			alg, err := dec.Algorithm()
+			require.NoError(t, err)
 			require.NotNil(t, alg)
 			assert.IsType(t, tc.otpType, alg)
 
@@ -273,3 +285,25 @@ func TestDecodedOTPAlgorithm(t *testing.T) {
 		})
 	}
 }
+
+func TestRegisteredHashAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	algorithm, err := otp.RegisterHashAlgorithm("SHA384", crypto.SHA384)
+	require.NoError(t, err)
+
+	alg := newTOTP(t, []byte("12345678901234567890"), totp.WithHashAlgorithm(algorithm))
+	uri := ToURI(alg, "foo@bar.com")
+
+	// WHEN
+	params, err := FromURI(uri)
+	require.NoError(t, err)
+
+	decoded, err := params.Algorithm()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Contains(t, uri, "algorithm=SHA384")
+	assert.Equal(t, alg.Generate(1111111109), decoded.Generate(1111111109))
+}
@@ -2,6 +2,7 @@ package otpauth
 
 import (
 	"encoding/base32"
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -11,6 +12,10 @@ import (
 	"github.com/dadrus/oath/otp"
 )
 
+// ErrUnsupportedPeriod is returned if the period of a TOTP algorithm is not a whole number
+// of seconds, which is the only period the otpauth uri format is able to express.
+var ErrUnsupportedPeriod = errors.New("period not supported by the otpauth uri format")
+
 type exporter struct {
 	key           []byte
 	hashAlgorithm string
@@ -66,6 +71,16 @@ func NewEncoder(alg otp.Algorithm, accountName string, opts ...EncoderOption) *E
 	return &Encoder{exp: exp}
 }
 
+// Validate returns ErrUnsupportedPeriod if the algorithm cannot be encoded without loss,
+// as its period is not a whole number of seconds.
+func (e *Encoder) Validate() error {
+	if e.exp.otpType == "totp" && (e.exp.period < time.Second || e.exp.period%time.Second != 0) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedPeriod, e.exp.period)
+	}
+
+	return nil
+}
+
 func (e *Encoder) Encode() string {
 	params := parameter{
 		"secret":    []string{strings.TrimRight(base32.StdEncoding.EncodeToString(e.exp.key), "=")},
@@ -100,6 +115,8 @@ func (e *Encoder) Encode() string {
 	return uri.String()
 }
 
+// ToURI encodes the given algorithm as otpauth uri. Periods of TOTP algorithms, which are
+// not a whole number of seconds, cannot be encoded. Use Encoder.Validate to check this.
 func ToURI(algorithm otp.Algorithm, accountName string, opts ...EncoderOption) string {
 	return NewEncoder(algorithm, accountName, opts...).Encode()
 }
@@ -12,6 +12,7 @@ import (
 
 	"github.com/dadrus/oath/otp"
 	"github.com/dadrus/oath/otp/mocks"
+	"github.com/dadrus/oath/totp"
 )
 
 func TestEncoderEncodeWithIssuer(t *testing.T) {
@@ -86,3 +87,36 @@ func TestEncoderEncodeWithoutIssuer(t *testing.T) {
 	assert.Equal(t, "7", uri.Query().Get("digits"))
 	assert.Equal(t, "10", uri.Query().Get("period"))
 }
+
+func TestEncoderValidate(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("12345678901234567890")
+
+	for _, tc := range []struct {
+		uc  string
+		alg otp.Algorithm
+		err error
+	}{
+		{uc: "totp with whole seconds period", alg: newTOTP(t, key, totp.WithTimeStep(45*time.Second))},
+		{uc: "totp with sub-second period", alg: newTOTP(t, key, totp.WithTimeStep(500*time.Millisecond)), err: ErrUnsupportedPeriod},
+		{uc: "totp with fractional period", alg: newTOTP(t, key, totp.WithTimeStep(1500*time.Millisecond)), err: ErrUnsupportedPeriod},
+		{uc: "hotp", alg: newHOTP(t, key)},
+	} {
+		tc := tc
+
+		t.Run(tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			err := NewEncoder(tc.alg, "foo").Validate()
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,405 @@
+package otpauth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dadrus/oath/otp"
+)
+
+// MigrationScheme is the uri scheme used by Google Authenticator to export multiple accounts
+// at once. The uri has the form otpauth-migration://offline?data=<payload>, with the payload
+// being a base64 encoded protocol buffers message. If there are too many accounts to fit
+// into a single QR code, the export is split into multiple batches.
+const MigrationScheme = "otpauth-migration"
+
+var (
+	ErrInvalidMigrationPayload       = errors.New("invalid migration payload")
+	ErrIncompleteMigrationBatch      = errors.New("incomplete migration batch")
+	ErrUnsupportedMigrationParameter = errors.New("parameter not supported by the migration format")
+)
+
+const (
+	// the period is not part of the migration payload. 30 seconds is always used
+	migrationPeriod         = 30 * time.Second
+	migrationVersion        = 1
+	defaultMigrationBatchSz = 10
+)
+
+// field numbers and enum values of the MigrationPayload protocol buffers message
+const (
+	payloadOTPParameters = 1
+	payloadVersion       = 2
+	payloadBatchSize     = 3
+	payloadBatchIndex    = 4
+	payloadBatchID       = 5
+
+	parameterSecret    = 1
+	parameterName      = 2
+	parameterIssuer    = 3
+	parameterAlgorithm = 4
+	parameterDigits    = 5
+	parameterType      = 6
+	parameterCounter   = 7
+
+	algorithmSHA1   = 1
+	algorithmSHA256 = 2
+	algorithmSHA512 = 3
+
+	digitsSix   = 1
+	digitsEight = 2
+
+	typeHOTP = 1
+	typeTOTP = 2
+)
+
+type migrationPayload struct {
+	parameters []*AlgorithmParameters
+	batchSize  int32
+	batchIndex int32
+	batchID    int32
+}
+
+// FromMigrationURI decodes the accounts from a single otpauth-migration uri. If the export has
+// been split into multiple batches, only the accounts from the given batch are returned. Use
+// FromMigrationURIs to decode all batches at once.
+func FromMigrationURI(value string) ([]*AlgorithmParameters, error) {
+	payload, err := decodeMigrationURI(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return payload.parameters, nil
+}
+
+// FromMigrationURIs decodes the accounts from all batches of an export. The uris can be given
+// in any order, but must all belong to the same export and cover all of its batches.
+func FromMigrationURIs(values ...string) ([]*AlgorithmParameters, error) {
+	var (
+		result  []*AlgorithmParameters
+		first   *migrationPayload
+		indices = map[int32]bool{}
+	)
+
+	for _, value := range values {
+		payload, err := decodeMigrationURI(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if first == nil {
+			first = payload
+		} else if payload.batchID != first.batchID || payload.batchSize != first.batchSize {
+			return nil, fmt.Errorf("%w: uris belong to different exports", ErrIncompleteMigrationBatch)
+		}
+
+		if payload.batchIndex < 0 || payload.batchIndex >= first.batches() {
+			return nil, fmt.Errorf("%w: batch %d of %d", ErrIncompleteMigrationBatch, payload.batchIndex, first.batches())
+		}
+
+		if indices[payload.batchIndex] {
+			return nil, fmt.Errorf("%w: duplicate batch %d", ErrIncompleteMigrationBatch, payload.batchIndex)
+		}
+
+		indices[payload.batchIndex] = true
+		result = append(result, payload.parameters...)
+	}
+
+	if first == nil {
+		return nil, fmt.Errorf("%w: no uris given", ErrIncompleteMigrationBatch)
+	}
+
+	if batches := int32(len(indices)); batches != first.batches() {
+		return nil, fmt.Errorf("%w: got %d of %d batches", ErrIncompleteMigrationBatch, batches, first.batches())
+	}
+
+	return result, nil
+}
+
+// batches returns the amount of batches of the export. Older exports do not set the batch
+// size if there is just one batch.
+func (p *migrationPayload) batches() int32 {
+	if p.batchSize == 0 {
+		return 1
+	}
+
+	return p.batchSize
+}
+
+// MigrationEntry describes an account to be exported with ToMigrationURIs
+type MigrationEntry struct {
+	Algorithm   otp.Algorithm
+	AccountName string
+	// Options can be used to set the issuer, as well as the counter for HOTP
+	Options []EncoderOption
+}
+
+type migrationEncoder struct {
+	batchSize int
+	batchID   *int32
+}
+
+type MigrationOption func(enc *migrationEncoder)
+
+// WithBatchSize sets the maximum amount of accounts per uri. Defaults to 10.
+func WithBatchSize(size int) MigrationOption {
+	return func(enc *migrationEncoder) {
+		if size > 0 {
+			enc.batchSize = size
+		}
+	}
+}
+
+// WithBatchID sets the id identifying the export. Defaults to a random value.
+func WithBatchID(id int32) MigrationOption {
+	return func(enc *migrationEncoder) {
+		enc.batchID = &id
+	}
+}
+
+// ToMigrationURIs encodes the given accounts into otpauth-migration uris, which can be imported
+// e.g. by Google Authenticator. The accounts are split into multiple batches (one uri each) according
+// to the configured batch size. Since the migration format is less expressive than the otpauth
+// format, only TOTP with a period of 30 seconds, or HOTP, with 6 or 8 digits and the hash algorithms
+// SHA1, SHA256 or SHA512 are supported.
+func ToMigrationURIs(entries []MigrationEntry, opts ...MigrationOption) ([]string, error) {
+	enc := &migrationEncoder{batchSize: defaultMigrationBatchSz}
+
+	for _, opt := range opts {
+		opt(enc)
+	}
+
+	batchID, err := enc.id()
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := make([][]byte, len(entries))
+
+	for idx, entry := range entries {
+		exp := &exporter{accountName: entry.AccountName}
+
+		for _, opt := range entry.Options {
+			opt(exp)
+		}
+
+		entry.Algorithm.Export(exp)
+
+		if parameters[idx], err = encodeMigrationParameters(exp); err != nil {
+			return nil, err
+		}
+	}
+
+	batches := (len(parameters) + enc.batchSize - 1) / enc.batchSize
+	uris := make([]string, 0, batches)
+
+	for batch := 0; batch < batches; batch++ {
+		end := (batch + 1) * enc.batchSize
+		if end > len(parameters) {
+			end = len(parameters)
+		}
+
+		var payload protoWriter
+
+		for _, params := range parameters[batch*enc.batchSize : end] {
+			payload.bytes(payloadOTPParameters, params)
+		}
+
+		payload.varint(payloadVersion, migrationVersion)
+		payload.varint(payloadBatchSize, uint64(batches))
+		payload.varint(payloadBatchIndex, uint64(batch))
+		payload.varint(payloadBatchID, uint64(int64(batchID)))
+
+		uri := &url.URL{
+			Scheme:   MigrationScheme,
+			Host:     "offline",
+			RawQuery: "data=" + url.QueryEscape(base64.StdEncoding.EncodeToString(payload.buf)),
+		}
+
+		uris = append(uris, uri.String())
+	}
+
+	return uris, nil
+}
+
+func (e *migrationEncoder) id() (int32, error) {
+	if e.batchID != nil {
+		return *e.batchID, nil
+	}
+
+	var buf [4]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return 0, err
+	}
+
+	return int32(binary.BigEndian.Uint32(buf[:])), nil
+}
+
+func encodeMigrationParameters(exp *exporter) ([]byte, error) {
+	var params protoWriter
+
+	params.bytes(parameterSecret, exp.key)
+	params.bytes(parameterName, []byte(exp.accountName))
+	params.bytes(parameterIssuer, []byte(exp.issuer))
+
+	switch strings.ToUpper(exp.hashAlgorithm) {
+	case "SHA1":
+		params.varint(parameterAlgorithm, algorithmSHA1)
+	case "SHA256":
+		params.varint(parameterAlgorithm, algorithmSHA256)
+	case "SHA512":
+		params.varint(parameterAlgorithm, algorithmSHA512)
+	default:
+		return nil, fmt.Errorf("%w: hash algorithm %s", ErrUnsupportedMigrationParameter, exp.hashAlgorithm)
+	}
+
+	switch exp.digits {
+	case 6: //nolint:gomnd
+		params.varint(parameterDigits, digitsSix)
+	case 8: //nolint:gomnd
+		params.varint(parameterDigits, digitsEight)
+	default:
+		return nil, fmt.Errorf("%w: %d digits", ErrUnsupportedMigrationParameter, exp.digits)
+	}
+
+	switch exp.otpType {
+	case TOTP:
+		if exp.period != migrationPeriod {
+			return nil, fmt.Errorf("%w: period %s", ErrUnsupportedMigrationParameter, exp.period)
+		}
+
+		params.varint(parameterType, typeTOTP)
+	case HOTP:
+		params.varint(parameterType, typeHOTP)
+		params.varint(parameterCounter, uint64(exp.counter))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedOTPAlgorithm, exp.otpType)
+	}
+
+	return params.buf, nil
+}
+
+func decodeMigrationURI(value string) (*migrationPayload, error) {
+	uri, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return nil, ErrUnsupportedURIScheme
+	}
+
+	if uri.Scheme != MigrationScheme {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedURIScheme, uri.Scheme)
+	}
+
+	// the base64 alphabet contains '+', which is decoded to a space if not escaped properly
+	data := strings.ReplaceAll(uri.Query().Get("data"), " ", "+")
+
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		if raw, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "=")); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMigrationPayload, err)
+		}
+	}
+
+	payload := &migrationPayload{}
+
+	err = readProtoFields(raw, func(field protoField) error {
+		switch field.number {
+		case payloadOTPParameters:
+			params, err := decodeMigrationParameters(field.data)
+			if err != nil {
+				return err
+			}
+
+			payload.parameters = append(payload.parameters, params)
+		case payloadBatchSize:
+			payload.batchSize = int32(field.varint)
+		case payloadBatchIndex:
+			payload.batchIndex = int32(field.varint)
+		case payloadBatchID:
+			payload.batchID = int32(field.varint)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errInvalidProtobuf) {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMigrationPayload, err)
+		}
+
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+func decodeMigrationParameters(data []byte) (*AlgorithmParameters, error) {
+	params := &AlgorithmParameters{hashAlgorithm: "SHA1", digits: otp.Digits(6)} //nolint:gomnd
+
+	var (
+		otpType uint64
+		name    string
+	)
+
+	err := readProtoFields(data, func(field protoField) error {
+		switch field.number {
+		case parameterSecret:
+			params.key = field.data
+		case parameterName:
+			name = string(field.data)
+		case parameterIssuer:
+			params.issuer = string(field.data)
+		case parameterAlgorithm:
+			switch field.varint {
+			case algorithmSHA1:
+				params.hashAlgorithm = "SHA1"
+			case algorithmSHA256:
+				params.hashAlgorithm = "SHA256"
+			case algorithmSHA512:
+				params.hashAlgorithm = "SHA512"
+			default:
+				return ErrUnsupportedHashAlgorithm
+			}
+		case parameterDigits:
+			if field.varint == digitsEight {
+				params.digits = otp.Digits(8) //nolint:gomnd
+			}
+		case parameterType:
+			otpType = field.varint
+		case parameterCounter:
+			params.counter = int64(field.varint)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch otpType {
+	case typeHOTP:
+		params.otpType = HOTP
+	case typeTOTP:
+		params.otpType = TOTP
+		params.period = migrationPeriod
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedOTPAlgorithm, otpType)
+	}
+
+	// some exporters encode the issuer in the name as well, like done in the otpauth label
+	if idx := strings.Index(name, ":"); idx != -1 {
+		if len(params.issuer) == 0 {
+			params.issuer = name[:idx]
+		}
+
+		name = strings.TrimSpace(name[idx+1:])
+	}
+
+	params.accountName = name
+
+	return params, nil
+}
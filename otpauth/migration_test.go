@@ -0,0 +1,222 @@
+package otpauth
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/hotp"
+	"github.com/dadrus/oath/otp"
+	"github.com/dadrus/oath/totp"
+)
+
+func TestFromMigrationURI(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		uc     string
+		vector string
+		result []*AlgorithmParameters
+		err    error
+	}{
+		{
+			uc:     "single totp entry",
+			vector: "otpauth-migration://offline?data=CjEKCkhlbGxvId6tvu8SGEV4YW1wbGU6YWxpY2VAZ29vZ2xlLmNvbRoHRXhhbXBsZTAC",
+			result: []*AlgorithmParameters{
+				{
+					key:           []byte("Hello!\xde\xad\xbe\xef"),
+					hashAlgorithm: "SHA1",
+					otpType:       TOTP,
+					period:        30 * time.Second,
+					digits:        otp.Digits(6),
+					issuer:        "Example",
+					accountName:   "alice@google.com",
+				},
+			},
+		},
+		{
+			uc:     "payload with unescaped characters",
+			vector: "otpauth-migration://offline?data=CjEKCkhlbGxvId6tvu8SGEV4YW1wbGU6YWxpY2VAZ29vZ2xlLmNvbRoHRXhhbXBsZTAC==",
+			result: []*AlgorithmParameters{
+				{
+					key:           []byte("Hello!\xde\xad\xbe\xef"),
+					hashAlgorithm: "SHA1",
+					otpType:       TOTP,
+					period:        30 * time.Second,
+					digits:        otp.Digits(6),
+					issuer:        "Example",
+					accountName:   "alice@google.com",
+				},
+			},
+		},
+		{uc: "wrong scheme", vector: "otpauth://offline?data=CjEKCkhlbGxv", err: ErrUnsupportedURIScheme},
+		{uc: "invalid base64", vector: "otpauth-migration://offline?data=%21%21", err: ErrInvalidMigrationPayload},
+		{uc: "truncated payload", vector: "otpauth-migration://offline?data=CjEKCkhlbGxv", err: ErrInvalidMigrationPayload},
+		// same entry as above, but with type unspecified
+		{
+			uc:     "unspecified otp type",
+			vector: "otpauth-migration://offline?data=Ci8KCkhlbGxvId6tvu8SGEV4YW1wbGU6YWxpY2VAZ29vZ2xlLmNvbRoHRXhhbXBsZQ",
+			err:    ErrUnsupportedOTPAlgorithm,
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			result, err := FromMigrationURI(tc.vector)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.result, result)
+			}
+		})
+	}
+}
+
+func newHOTP(t *testing.T, key []byte, opts ...hotp.Option) *hotp.Algorithm {
+	t.Helper()
+
+	alg, err := hotp.New(key, opts...)
+	require.NoError(t, err)
+
+	return alg
+}
+
+func newTOTP(t *testing.T, key []byte, opts ...totp.Option) *totp.Algorithm {
+	t.Helper()
+
+	alg, err := totp.New(key, opts...)
+	require.NoError(t, err)
+
+	return alg
+}
+
+func TestMigratedAlgorithmWith80BitKey(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	params, err := FromMigrationURI(
+		"otpauth-migration://offline?data=CjEKCkhlbGxvId6tvu8SGEV4YW1wbGU6YWxpY2VAZ29vZ2xlLmNvbRoHRXhhbXBsZTAC")
+	require.NoError(t, err)
+	require.Len(t, params, 1)
+
+	// WHEN
+	alg, err := params[0].Algorithm()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Len(t, alg.Generate(time.Now().Unix()), 6)
+}
+
+func TestMigrationURIsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	entries := []MigrationEntry{
+		{Algorithm: newTOTP(t, key), AccountName: "alice@example.com", Options: []EncoderOption{WithIssuer("ACME")}},
+		{Algorithm: newHOTP(t, key, hotp.WithDigits(8), hotp.WithHashAlgorithm(otp.SHA256)), AccountName: "bob", Options: []EncoderOption{WithCounter(42)}},
+		{Algorithm: newTOTP(t, key, totp.WithHashAlgorithm(otp.SHA512)), AccountName: "carol"},
+	}
+
+	// WHEN
+	uris, err := ToMigrationURIs(entries, WithBatchSize(2), WithBatchID(-7))
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, uris, 2)
+
+	for _, uri := range uris {
+		assert.True(t, strings.HasPrefix(uri, "otpauth-migration://offline?data="))
+	}
+
+	result, err := FromMigrationURIs(uris[1], uris[0])
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+
+	assert.Equal(t, []*AlgorithmParameters{
+		{key: key, hashAlgorithm: "SHA512", otpType: TOTP, period: 30 * time.Second, digits: 6, accountName: "carol"},
+		{key: key, hashAlgorithm: "SHA1", otpType: TOTP, period: 30 * time.Second, digits: 6, issuer: "ACME", accountName: "alice@example.com"},
+		{key: key, hashAlgorithm: "SHA256", otpType: HOTP, digits: 8, counter: 42, accountName: "bob"},
+	}, result)
+
+	_, err = FromMigrationURIs(uris[1])
+	require.ErrorIs(t, err, ErrIncompleteMigrationBatch)
+
+	_, err = FromMigrationURIs(uris[0], uris[0])
+	require.ErrorIs(t, err, ErrIncompleteMigrationBatch)
+
+	other, err := ToMigrationURIs(entries, WithBatchSize(2), WithBatchID(8))
+	require.NoError(t, err)
+
+	_, err = FromMigrationURIs(uris[0], other[1])
+	require.ErrorIs(t, err, ErrIncompleteMigrationBatch)
+}
+
+func TestFromMigrationURIsWithBatchIndexOutOfRange(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	entries := []MigrationEntry{
+		{Algorithm: newTOTP(t, []byte("12345678901234567890")), AccountName: "alice"},
+		{Algorithm: newTOTP(t, []byte("12345678901234567890")), AccountName: "bob"},
+	}
+
+	uris, err := ToMigrationURIs(entries, WithBatchSize(1), WithBatchID(7))
+	require.NoError(t, err)
+	require.Len(t, uris, 2)
+
+	// WHEN -> batch 1 of 2 is replaced by batch 5, which does not exist
+	_, err = FromMigrationURIs(uris[0], migrationURI(t, 2, 5, 7))
+
+	// THEN
+	require.ErrorIs(t, err, ErrIncompleteMigrationBatch)
+
+	// WHEN -> older exports consisting of a single batch do not set the batch size
+	_, err = FromMigrationURIs(migrationURI(t, 0, 1, 7))
+
+	// THEN
+	require.ErrorIs(t, err, ErrIncompleteMigrationBatch)
+}
+
+// migrationURI creates an otpauth-migration uri without accounts, but with the given batch information
+func migrationURI(t *testing.T, size, index, id uint64) string {
+	t.Helper()
+
+	var payload protoWriter
+
+	payload.varint(payloadVersion, migrationVersion)
+	payload.varint(payloadBatchSize, size)
+	payload.varint(payloadBatchIndex, index)
+	payload.varint(payloadBatchID, id)
+
+	return MigrationScheme + "://offline?data=" + url.QueryEscape(base64.StdEncoding.EncodeToString(payload.buf))
+}
+
+func TestToMigrationURIsWithUnsupportedParameters(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("12345678901234567890")
+
+	for _, tc := range []struct {
+		uc  string
+		alg otp.Algorithm
+	}{
+		{uc: "totp period", alg: newTOTP(t, key, totp.WithTimeStep(60*time.Second))},
+		{uc: "digits", alg: newHOTP(t, key, hotp.WithDigits(7))},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			_, err := ToMigrationURIs([]MigrationEntry{{Algorithm: tc.alg, AccountName: "foo"}})
+
+			// THEN
+			require.ErrorIs(t, err, ErrUnsupportedMigrationParameter)
+		})
+	}
+}
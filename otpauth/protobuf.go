@@ -0,0 +1,102 @@
+package otpauth
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// minimal implementation of the protocol buffers wire format, sufficient to encode and
+// decode the payload of the otpauth-migration scheme.
+// See https://protobuf.dev/programming-guides/encoding/ for details
+
+var errInvalidProtobuf = errors.New("invalid protobuf encoding")
+
+const (
+	wireVarint = 0
+	wireI64    = 1
+	wireLen    = 2
+	wireI32    = 5
+)
+
+type protoField struct {
+	number   uint64
+	wireType uint64
+	varint   uint64
+	data     []byte
+}
+
+type protoWriter struct {
+	buf []byte
+}
+
+func (w *protoWriter) varint(number uint64, value uint64) {
+	if value == 0 {
+		return
+	}
+
+	w.buf = binary.AppendUvarint(w.buf, number<<3|wireVarint)
+	w.buf = binary.AppendUvarint(w.buf, value)
+}
+
+func (w *protoWriter) bytes(number uint64, value []byte) {
+	if len(value) == 0 {
+		return
+	}
+
+	w.buf = binary.AppendUvarint(w.buf, number<<3|wireLen)
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(value)))
+	w.buf = append(w.buf, value...)
+}
+
+func readProtoFields(data []byte, handle func(field protoField) error) error {
+	const (
+		i64Size = 8
+		i32Size = 4
+	)
+
+	for len(data) != 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errInvalidProtobuf
+		}
+
+		data = data[n:]
+		field := protoField{number: tag >> 3, wireType: tag & 0x7} //nolint:gomnd
+
+		switch field.wireType {
+		case wireVarint:
+			if field.varint, n = binary.Uvarint(data); n <= 0 {
+				return errInvalidProtobuf
+			}
+
+			data = data[n:]
+		case wireLen:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errInvalidProtobuf
+			}
+
+			field.data = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case wireI64, wireI32:
+			size := i64Size
+			if field.wireType == wireI32 {
+				size = i32Size
+			}
+
+			if len(data) < size {
+				return errInvalidProtobuf
+			}
+
+			data = data[size:]
+		default:
+			return errInvalidProtobuf
+		}
+
+		if err := handle(field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package otpauth
+
+import (
+	"fmt"
+
+	"github.com/dadrus/oath/otp"
+)
+
+// Destroy overwrites the key with zeros. Algorithms created from the parameters before
+// hold their own copy of the key, which has to be destroyed separately.
+func (d *AlgorithmParameters) Destroy() { otp.Wipe(d.key) }
+
+func (d AlgorithmParameters) String() string {
+	return fmt.Sprintf("%s(%s:%s)", d.otpType, d.issuer, d.accountName)
+}
+
+func (d AlgorithmParameters) GoString() string {
+	return fmt.Sprintf(
+		"otpauth.AlgorithmParameters{key: %s, hashAlgorithm: %q, otpType: %q, period: %s, "+
+			"digits: %d, counter: %d, issuer: %q, accountName: %q}",
+		otp.Redacted, d.hashAlgorithm, d.otpType, d.period, d.digits, d.counter, d.issuer, d.accountName,
+	)
+}
+
+func (d AlgorithmParameters) Format(f fmt.State, verb rune) { otp.FormatRedacted(f, verb, d) }
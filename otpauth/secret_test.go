@@ -0,0 +1,24 @@
+package otpauth
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlgorithmParametersDestroy(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	params, err := FromURI("otpauth://totp/ACME:john@example.com?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&issuer=ACME")
+	require.NoError(t, err)
+
+	// WHEN
+	params.Destroy()
+
+	// THEN
+	assert.Equal(t, make([]byte, 20), params.Key())
+	assert.Equal(t, "totp(ACME:john@example.com)", fmt.Sprintf("%x", params))
+}
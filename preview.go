@@ -0,0 +1,30 @@
+package oath
+
+import (
+	"crypto/cipher"
+	"errors"
+
+	"github.com/dadrus/oath/totp"
+)
+
+var ErrPreviewNotSupported = errors.New("preview not supported")
+
+type previewer interface {
+	Preview() (totp.Preview, error)
+}
+
+// Preview returns the otp value the authenticator of the user currently shows for the given
+// TOTP blob (blobValue), the following one, as well as how long the current value remains
+// valid. The deviation of the clock of the user tracked in the blob is taken into account.
+// HOTP blobs are not supported. As with Verify, only options not affecting the blob
+// configuration itself, like WithClock, are taken into account.
+func Preview(blobValue string, cipher cipher.AEAD, opts ...Option) (totp.Preview, error) {
+	tok, err := Open(blobValue, cipher, opts...)
+	if err != nil {
+		return totp.Preview{}, err
+	}
+
+	defer tok.Destroy()
+
+	return tok.Preview()
+}
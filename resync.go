@@ -0,0 +1,62 @@
+package oath
+
+import (
+	"context"
+	"crypto/cipher"
+	"errors"
+)
+
+var ErrResyncNotSupported = errors.New("resynchronization not supported")
+
+type resyncer interface {
+	Resync(ctx context.Context, values []string, window int) error
+}
+
+// WithResyncWindow sets the amount of counters searched by Resync. Defaults to
+// hotp.DefaultResyncWindow.
+func WithResyncWindow(size int) Option {
+	return func(o *config) {
+		if size > 0 {
+			o.settings.resyncWindow = size
+		}
+	}
+}
+
+// Resync resynchronizes the counter of a HOTP blob (blobValue) with the token of the user,
+// as described in RFC 4226, section 7.4. This is required if the user generated too many
+// otp values without using them, so that the counter is beyond the validity window. To
+// resynchronize, the user has to provide two or three consecutive otp values (otpValues),
+// which are searched within a large look-ahead window (see WithResyncWindow). On success,
+// the counter is advanced to follow the last given value.
+// As with Verify, the updated sealed blob is returned also on failure and a locked blob
+// cannot be resynchronized. TOTP blobs are not supported.
+func Resync(otpValues []string, blobValue string, cipher cipher.AEAD, opts ...Option) (string, error) {
+	return ResyncContext(context.Background(), otpValues, blobValue, cipher, opts...)
+}
+
+// ResyncContext works like Resync, but aborts the search with otp.ErrCanceled if the given
+// context is done.
+func ResyncContext(
+	ctx context.Context, otpValues []string, blobValue string, cipher cipher.AEAD, opts ...Option,
+) (string, error) {
+	tok, err := Open(blobValue, cipher, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	defer tok.Destroy()
+
+	err = tok.ResyncContext(ctx, otpValues)
+	if errors.Is(err, ErrResyncNotSupported) {
+		return "", err
+	} else if !tok.Modified() {
+		return blobValue, err
+	}
+
+	raw, mErr := tok.Seal(cipher)
+	if mErr != nil {
+		return "", mErr
+	}
+
+	return raw, err
+}
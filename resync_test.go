@@ -0,0 +1,66 @@
+package oath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/otp"
+)
+
+func TestResync(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+	alg := newHOTP(t, key)
+
+	blob, err := HOTP.New(c, WithKey(key), WithWorkSkew(1), WithInitialSkew(1))
+	require.NoError(t, err)
+
+	// the user pressed the button of the token too many times
+	_, _, err = Verify(alg.Generate(500), blob, c)
+	require.ErrorIs(t, err, otp.ErrValidation)
+
+	// WHEN
+	_, err = Resync([]string{alg.Generate(500), alg.Generate(502)}, blob, c)
+
+	// THEN
+	require.ErrorIs(t, err, otp.ErrValidation)
+
+	// WHEN
+	_, err = Resync([]string{alg.Generate(500), alg.Generate(501)}, blob, c, WithResyncWindow(100))
+
+	// THEN
+	require.ErrorIs(t, err, otp.ErrValidation)
+
+	// WHEN
+	blob, err = Resync([]string{alg.Generate(500), alg.Generate(501)}, blob, c)
+
+	// THEN
+	require.NoError(t, err)
+
+	_, _, err = Verify(alg.Generate(501), blob, c)
+	require.ErrorIs(t, err, otp.ErrReplayed)
+
+	_, synced, err := Verify(alg.Generate(502), blob, c)
+	require.NoError(t, err)
+	require.True(t, synced)
+}
+
+func TestResyncTOTP(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	c := newAEAD(t)
+
+	blob, err := TOTP.New(c, WithKey([]byte("12345678901234567890")))
+	require.NoError(t, err)
+
+	// WHEN
+	_, err = Resync([]string{"123456", "234567"}, blob, c)
+
+	// THEN
+	require.ErrorIs(t, err, ErrResyncNotSupported)
+}
@@ -0,0 +1,101 @@
+package oath
+
+import (
+	"context"
+	"crypto/cipher"
+	"errors"
+)
+
+var (
+	// ErrConflict is returned by a Store if the blob has been modified concurrently
+	ErrConflict = errors.New("blob modified concurrently")
+	// ErrAccountNotFound is returned by a Store if there is no blob for the given account
+	ErrAccountNotFound = errors.New("account not found")
+)
+
+// Store persists sealed blobs keyed by account and supports optimistic concurrency control.
+// Implementations for memory and database/sql can be found in the store package.
+type Store interface {
+	// Load returns the sealed blob of the given account together with its version.
+	// Returns ErrAccountNotFound if there is no blob for the given account.
+	Load(ctx context.Context, account string) (string, int64, error)
+
+	// Save stores the sealed blob of the given account, but only if the stored version still
+	// equals the given one (compare and swap). Otherwise, ErrConflict is returned. A version of
+	// 0 creates a new entry and fails with ErrConflict if there is already one for the account.
+	Save(ctx context.Context, account string, blob string, version int64) error
+}
+
+// WithMaxRetries sets how often VerifyStored and ResyncStored retry the operation if the blob
+// has been modified concurrently. Defaults to 3.
+func WithMaxRetries(retries int) Option {
+	return func(o *config) {
+		if retries >= 0 {
+			o.settings.maxRetries = &retries
+		}
+	}
+}
+
+// VerifyStored works like Verify, but loads the blob of the given account from the store and
+// writes the updated blob back. If the blob has been modified concurrently, e.g. by another
+// login attempt with the same otp value, the whole operation is retried with the new blob.
+// This way, an otp value can be used only once even by concurrent requests.
+func VerifyStored(
+	ctx context.Context, store Store, account string, otpValue string, cipher cipher.AEAD, opts ...Option,
+) (bool, error) {
+	var synced bool
+
+	err := withStore(ctx, store, account, opts, func(blobValue string) (string, error) {
+		var (
+			updated string
+			err     error
+		)
+
+		updated, synced, err = VerifyContext(ctx, otpValue, blobValue, cipher, opts...)
+
+		return updated, err
+	})
+
+	return synced, err
+}
+
+// ResyncStored works like Resync, but makes use of the given store, like VerifyStored.
+func ResyncStored(
+	ctx context.Context, store Store, account string, otpValues []string, cipher cipher.AEAD, opts ...Option,
+) error {
+	return withStore(ctx, store, account, opts, func(blobValue string) (string, error) {
+		return ResyncContext(ctx, otpValues, blobValue, cipher, opts...)
+	})
+}
+
+func withStore(
+	ctx context.Context, store Store, account string, opts []Option, operation func(blob string) (string, error),
+) error {
+	const defaultMaxRetries = 3
+
+	retries := defaultMaxRetries
+	if configured := settingsFrom(opts).maxRetries; configured != nil {
+		retries = *configured
+	}
+
+	for attempt := 0; ; attempt++ {
+		blobValue, version, err := store.Load(ctx, account)
+		if err != nil {
+			return err
+		}
+
+		updated, opErr := operation(blobValue)
+		if len(updated) == 0 || updated == blobValue {
+			return opErr
+		}
+
+		err = store.Save(ctx, account, updated, version)
+		if err == nil {
+			return opErr
+		}
+
+		if !errors.Is(err, ErrConflict) || attempt >= retries {
+			return err
+		}
+	}
+}
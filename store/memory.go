@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dadrus/oath"
+)
+
+type entry struct {
+	blob    string
+	version int64
+}
+
+// Memory is an in-memory implementation of oath.Store. It is safe for concurrent use.
+type Memory struct {
+	mut     sync.Mutex
+	entries map[string]entry
+}
+
+func NewMemory() *Memory {
+	return &Memory{entries: make(map[string]entry)}
+}
+
+func (m *Memory) Load(ctx context.Context, account string) (string, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return "", 0, err
+	}
+
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	e, ok := m.entries[account]
+	if !ok {
+		return "", 0, oath.ErrAccountNotFound
+	}
+
+	return e.blob, e.version, nil
+}
+
+func (m *Memory) Save(ctx context.Context, account string, blob string, version int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	if e := m.entries[account]; e.version != version {
+		return oath.ErrConflict
+	}
+
+	m.entries[account] = entry{blob: blob, version: version + 1}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/dadrus/oath"
+)
+
+// Placeholder returns the bind parameter placeholder for the n-th (starting with 1)
+// parameter of a statement, as expected by the used database driver.
+type Placeholder func(n int) string
+
+var (
+	// QuestionPlaceholder is used e.g. by SQLite and MySQL
+	QuestionPlaceholder Placeholder = func(int) string { return "?" }
+	// DollarPlaceholder is used e.g. by PostgreSQL
+	DollarPlaceholder Placeholder = func(n int) string { return fmt.Sprintf("$%d", n) }
+)
+
+type SQLOption func(s *SQL)
+
+// WithTable sets the name of the table used to store the blobs. Defaults to otp_blobs.
+func WithTable(name string) SQLOption {
+	return func(s *SQL) {
+		if len(name) != 0 {
+			s.table = name
+		}
+	}
+}
+
+// WithPlaceholder sets the placeholder style. Defaults to QuestionPlaceholder.
+func WithPlaceholder(placeholder Placeholder) SQLOption {
+	return func(s *SQL) {
+		if placeholder != nil {
+			s.placeholder = placeholder
+		}
+	}
+}
+
+// SQL implements oath.Store on top of database/sql. The blobs are stored in a table
+// with the following structure (see also CreateTable):
+//
+//	account VARCHAR(255) PRIMARY KEY, data TEXT NOT NULL, version BIGINT NOT NULL
+//
+// Optimistic concurrency is implemented by conditional updates on the version column.
+type SQL struct {
+	db          *sql.DB
+	table       string
+	placeholder Placeholder
+}
+
+func NewSQL(db *sql.DB, opts ...SQLOption) *SQL {
+	store := &SQL{db: db, table: "otp_blobs", placeholder: QuestionPlaceholder}
+
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	return store
+}
+
+// CreateTable creates the table used to store the blobs if it does not exist yet
+func (s *SQL) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s "+
+			"(account VARCHAR(255) PRIMARY KEY, data TEXT NOT NULL, version BIGINT NOT NULL)",
+		s.table))
+
+	return err
+}
+
+func (s *SQL) Load(ctx context.Context, account string) (string, int64, error) {
+	var (
+		blob    string
+		version int64
+	)
+
+	err := s.db.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT data, version FROM %s WHERE account = %s", s.table, s.placeholder(1)),
+		account,
+	).Scan(&blob, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 0, oath.ErrAccountNotFound
+	} else if err != nil {
+		return "", 0, err
+	}
+
+	return blob, version, nil
+}
+
+func (s *SQL) Save(ctx context.Context, account string, blob string, version int64) error {
+	if version == 0 {
+		return s.insert(ctx, account, blob)
+	}
+
+	res, err := s.db.ExecContext(ctx,
+		fmt.Sprintf("UPDATE %s SET data = %s, version = version + 1 WHERE account = %s AND version = %s",
+			s.table, s.placeholder(1), s.placeholder(2), s.placeholder(3)), //nolint:gomnd
+		blob, account, version,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return oath.ErrConflict
+	}
+
+	return nil
+}
+
+func (s *SQL) insert(ctx context.Context, account string, blob string) error {
+	_, err := s.db.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (account, data, version) VALUES (%s, %s, 1)",
+			s.table, s.placeholder(1), s.placeholder(2)),
+		account, blob,
+	)
+	if err == nil {
+		return nil
+	}
+
+	// the error caused by a violated primary key constraint is driver specific.
+	// So check whether there is already an entry for the account.
+	if _, _, lErr := s.Load(ctx, account); lErr == nil {
+		return oath.ErrConflict
+	}
+
+	return err
+}
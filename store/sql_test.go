@@ -0,0 +1,163 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDriver is a minimal in-memory stand-in for a database, which understands
+// just the statements issued by the SQL store.
+type fakeDriver struct {
+	mut        sync.Mutex
+	statements []string
+	rows       map[string]fakeRow
+}
+
+type fakeRow struct {
+	data    string
+	version int64
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{d: c.d, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mut.Lock()
+	defer s.d.mut.Unlock()
+
+	s.d.statements = append(s.d.statements, s.query)
+
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE"):
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(s.query, "INSERT"):
+		account := args[0].(string) //nolint:forcetypeassert
+		if _, ok := s.d.rows[account]; ok {
+			return nil, errors.New("UNIQUE constraint failed")
+		}
+
+		s.d.rows[account] = fakeRow{data: args[1].(string), version: 1} //nolint:forcetypeassert
+
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "UPDATE"):
+		account := args[1].(string) //nolint:forcetypeassert
+
+		row, ok := s.d.rows[account]
+		if !ok || row.version != args[2].(int64) { //nolint:forcetypeassert
+			return driver.RowsAffected(0), nil
+		}
+
+		s.d.rows[account] = fakeRow{data: args[0].(string), version: row.version + 1} //nolint:forcetypeassert
+
+		return driver.RowsAffected(1), nil
+	default:
+		return nil, errors.New("unexpected statement")
+	}
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mut.Lock()
+	defer s.d.mut.Unlock()
+
+	s.d.statements = append(s.d.statements, s.query)
+
+	if !strings.HasPrefix(s.query, "SELECT") {
+		return nil, errors.New("unexpected statement")
+	}
+
+	row, ok := s.d.rows[args[0].(string)] //nolint:forcetypeassert
+
+	return &fakeRows{row: row, done: !ok}, nil
+}
+
+type fakeRows struct {
+	row  fakeRow
+	done bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"data", "version"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+
+	dest[0], dest[1] = r.row.data, r.row.version
+	r.done = true
+
+	return nil
+}
+
+func newFakeDB(t *testing.T) (*sql.DB, *fakeDriver) {
+	t.Helper()
+
+	drv := &fakeDriver{rows: make(map[string]fakeRow)}
+	db := sql.OpenDB(fakeConnector{d: drv})
+
+	t.Cleanup(func() { db.Close() })
+
+	return db, drv
+}
+
+type fakeConnector struct{ d *fakeDriver }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{d: c.d}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return c.d }
+
+func TestSQL(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	db, _ := newFakeDB(t)
+	store := NewSQL(db)
+	require.NoError(t, store.CreateTable(context.Background()))
+
+	// WHEN/THEN
+	testStore(t, store)
+}
+
+func TestSQLOptions(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	db, drv := newFakeDB(t)
+	store := NewSQL(db, WithTable("tokens"), WithPlaceholder(DollarPlaceholder))
+
+	// WHEN
+	err := store.Save(context.Background(), "alice", "blob", 0)
+	require.NoError(t, err)
+
+	err = store.Save(context.Background(), "alice", "blob", 1)
+	require.NoError(t, err)
+
+	// THEN
+	assert.Equal(t, []string{
+		"INSERT INTO tokens (account, data, version) VALUES ($1, $2, 1)",
+		"UPDATE tokens SET data = $1, version = version + 1 WHERE account = $2 AND version = $3",
+	}, drv.statements)
+}
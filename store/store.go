@@ -0,0 +1,2 @@
+// Package store provides implementations of the oath.Store interface
+package store
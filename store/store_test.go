@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath"
+)
+
+func testStore(t *testing.T, store oath.Store) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	// GIVEN no entry
+	_, _, err := store.Load(ctx, "alice")
+	require.ErrorIs(t, err, oath.ErrAccountNotFound)
+
+	// WHEN an entry is created
+	require.NoError(t, store.Save(ctx, "alice", "blob-1", 0))
+
+	// THEN it can be loaded
+	blob, version, err := store.Load(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "blob-1", blob)
+	assert.Equal(t, int64(1), version)
+
+	// and cannot be created twice
+	require.ErrorIs(t, store.Save(ctx, "alice", "blob-x", 0), oath.ErrConflict)
+
+	// WHEN the entry is updated
+	require.NoError(t, store.Save(ctx, "alice", "blob-2", version))
+
+	// THEN the version is incremented
+	blob, version, err = store.Load(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "blob-2", blob)
+	assert.Equal(t, int64(2), version)
+
+	// and updates based on an outdated version fail
+	require.ErrorIs(t, store.Save(ctx, "alice", "blob-3", 1), oath.ErrConflict)
+
+	blob, _, err = store.Load(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "blob-2", blob)
+
+	// entries of other accounts are not affected
+	_, _, err = store.Load(ctx, "bob")
+	require.ErrorIs(t, err, oath.ErrAccountNotFound)
+	require.ErrorIs(t, store.Save(ctx, "bob", "blob-1", 1), oath.ErrConflict)
+}
+
+func TestMemory(t *testing.T) {
+	t.Parallel()
+
+	testStore(t, NewMemory())
+}
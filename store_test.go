@@ -0,0 +1,154 @@
+package oath
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/otp"
+)
+
+type testStore struct {
+	mut      sync.Mutex
+	blob     string
+	version  int64
+	saves    int
+	conflict bool
+}
+
+func (s *testStore) Load(_ context.Context, _ string) (string, int64, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.version == 0 {
+		return "", 0, ErrAccountNotFound
+	}
+
+	return s.blob, s.version, nil
+}
+
+func (s *testStore) Save(_ context.Context, _ string, blob string, version int64) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.saves++
+
+	if s.conflict || s.version != version {
+		return ErrConflict
+	}
+
+	s.blob = blob
+	s.version++
+
+	return nil
+}
+
+func TestVerifyStoredConcurrently(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	const attempts = 10
+
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+
+	blob, err := HOTP.New(c, WithKey(key))
+	require.NoError(t, err)
+
+	store := &testStore{}
+	require.NoError(t, store.Save(context.Background(), "alice", blob, 0))
+
+	otpValue := newHOTP(t, key).Generate(0)
+
+	var (
+		wg        sync.WaitGroup
+		mut       sync.Mutex
+		successes int
+	)
+
+	// WHEN
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := VerifyStored(context.Background(), store, "alice", otpValue, c, WithMaxRetries(attempts))
+
+			mut.Lock()
+			defer mut.Unlock()
+
+			if err == nil {
+				successes++
+			} else {
+				assert.ErrorIs(t, err, otp.ErrValidation)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// THEN
+	assert.Equal(t, 1, successes)
+}
+
+func TestVerifyStoredFails(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+
+	blob, err := HOTP.New(c, WithKey(key))
+	require.NoError(t, err)
+
+	for uc, tc := range map[string]struct {
+		store  *testStore
+		opts   []Option
+		assert func(t *testing.T, err error, store *testStore)
+	}{
+		"unknown account": {
+			store: &testStore{},
+			assert: func(t *testing.T, err error, store *testStore) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrAccountNotFound)
+				assert.Equal(t, 0, store.saves)
+			},
+		},
+		"conflict after all retries": {
+			store: &testStore{blob: blob, version: 1, conflict: true},
+			opts:  []Option{WithMaxRetries(2)},
+			assert: func(t *testing.T, err error, store *testStore) {
+				t.Helper()
+
+				require.ErrorIs(t, err, ErrConflict)
+				assert.Equal(t, 3, store.saves)
+			},
+		},
+		"undecryptable blob": {
+			store: &testStore{blob: "foo", version: 1},
+			assert: func(t *testing.T, err error, store *testStore) {
+				t.Helper()
+
+				require.Error(t, err)
+				assert.Equal(t, 0, store.saves)
+			},
+		},
+	} {
+		tc := tc
+
+		t.Run(uc, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			_, err := VerifyStored(
+				context.Background(), tc.store, "alice", newHOTP(t, key).Generate(0), c, tc.opts...)
+
+			// THEN
+			tc.assert(t, err, tc.store)
+		})
+	}
+}
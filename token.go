@@ -0,0 +1,241 @@
+package oath
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dadrus/oath/hotp"
+	"github.com/dadrus/oath/otp"
+	"github.com/dadrus/oath/totp"
+)
+
+// Metadata describes the configuration and state of a Token. Zero values denote
+// the defaults of the corresponding algorithm.
+type Metadata struct {
+	Type           OTPType
+	HashAlgorithm  otp.HashAlgorithm
+	Digits         otp.Digits
+	Period         time.Duration
+	Counter        int64
+	Synchronized   bool
+	FailedAttempts int
+	// LockedUntil is the zero time if the token is not locked by a backoff
+	LockedUntil time.Time
+}
+
+// Token is an unsealed blob. It allows performing several operations on a blob without
+// unsealing and sealing it each time. All changes are tracked, so the blob needs to be
+// sealed only if the token has been modified. A Token is safe for concurrent use.
+type Token struct {
+	mut      sync.Mutex
+	data     *config
+	blb      Blob
+	modified bool
+}
+
+// Open unseals the given blob (blobValue) by making use of the provided cipher. As with
+// Verify, only options not affecting the blob configuration itself, as well as WithBackoff
+// and WithLockout are taken into account.
+func Open(blobValue string, cipher cipher.AEAD, opts ...Option) (*Token, error) {
+	data, blb, err := blob(blobValue, cipher, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Token{data: data, blb: blb}, nil
+}
+
+// Verify verifies the given otp value. Failed attempts are tracked as well and lock the
+// token, if configured (see WithBackoff and WithLockout). If the token is locked, a
+// LockedError is returned.
+func (t *Token) Verify(otpValue string) error {
+	return t.VerifyContext(context.Background(), otpValue)
+}
+
+// VerifyContext works like Verify, but aborts the verification with otp.ErrCanceled if
+// the given context is done. Only wrong otp values count as failed attempts. Aborted
+// verifications and failures of the key provider leave the token unmodified.
+func (t *Token) VerifyContext(ctx context.Context, otpValue string) error {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	now := t.data.settings.now()
+
+	if err := t.data.checkLocked(now); err != nil {
+		return err
+	}
+
+	err := t.blb.VerifyContext(ctx, otpValue)
+	if err != nil {
+		if countsAsFailure(err) {
+			t.modified = true
+			t.data.registerFailure(now)
+		}
+
+		return err
+	}
+
+	t.modified = true
+	t.data.resetFailures()
+
+	return nil
+}
+
+// Resync resynchronizes the counter of a HOTP token. See the Resync function for details.
+func (t *Token) Resync(otpValues []string) error {
+	return t.ResyncContext(context.Background(), otpValues)
+}
+
+// ResyncContext works like Resync, but aborts the search with otp.ErrCanceled if the given
+// context is done.
+func (t *Token) ResyncContext(ctx context.Context, otpValues []string) error {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	rs, ok := t.blb.(resyncer)
+	if !ok {
+		return ErrResyncNotSupported
+	}
+
+	now := t.data.settings.now()
+
+	if err := t.data.checkLocked(now); err != nil {
+		return err
+	}
+
+	window := t.data.settings.resyncWindow
+	if window == 0 {
+		window = hotp.DefaultResyncWindow
+	}
+
+	err := rs.Resync(ctx, otpValues, window)
+	if err != nil {
+		if countsAsFailure(err) {
+			t.modified = true
+			t.data.registerFailure(now)
+		}
+
+		return err
+	}
+
+	t.modified = true
+	t.data.resetFailures()
+
+	return nil
+}
+
+// countsAsFailure tells whether the error has been caused by a wrong otp value, which
+// counts as failed attempt, and not e.g. by a canceled context or a failing key handle.
+func countsAsFailure(err error) bool {
+	return errors.Is(err, otp.ErrValidation) || errors.Is(err, otp.ErrMalformed)
+}
+
+// Unlock resets the failed attempts and with that unlocks the token.
+func (t *Token) Unlock() {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if t.data.FailedAttempts != 0 || t.data.LockedUntil != 0 {
+		t.data.resetFailures()
+		t.modified = true
+	}
+}
+
+// Export exports the token in the OTPAUTH format (first return value), as well as
+// the key base32 encoded (second return value). Returns ErrKeyNotExportable if the key
+// is kept by a key provider.
+func (t *Token) Export(account, issuer string) (string, string, error) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	uri, err := t.blb.OTPURI(account, issuer)
+	if err != nil {
+		return "", "", err
+	}
+
+	encoded := base32.StdEncoding.EncodeToString(t.data.Key)
+
+	return uri, strings.TrimRight(encoded, "="), nil
+}
+
+// Preview returns the otp value of a TOTP token as shown by the authenticator of the user.
+// See the Preview function for details.
+func (t *Token) Preview() (totp.Preview, error) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	pv, ok := t.blb.(previewer)
+	if !ok {
+		return totp.Preview{}, ErrPreviewNotSupported
+	}
+
+	return pv.Preview()
+}
+
+// Synchronized tells whether there was at least one successful verification.
+func (t *Token) Synchronized() bool {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	return t.blb.Synchronized()
+}
+
+func (t *Token) Metadata() Metadata {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	md := Metadata{
+		Type:           OTPType(t.data.Type),
+		HashAlgorithm:  t.data.HashAlgorithm,
+		Digits:         t.data.Digits,
+		Period:         t.data.Period,
+		Counter:        t.data.Counter,
+		Synchronized:   t.data.Synchronized,
+		FailedAttempts: t.data.FailedAttempts,
+	}
+
+	if t.data.LockedUntil != 0 {
+		md.LockedUntil = time.UnixMilli(t.data.LockedUntil)
+	}
+
+	return md
+}
+
+// Modified tells whether the token has been modified since it has been opened or sealed
+// the last time.
+func (t *Token) Modified() bool {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	return t.modified
+}
+
+// Destroy overwrites the key material held by the token with zeros. The token must not be
+// used afterwards. Call it after sealing the token for the last time.
+func (t *Token) Destroy() {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	t.data.wipe()
+}
+
+// Seal seals the token by making use of the given cipher. If the cipher is a Keyring,
+// the blob is sealed with its current key.
+func (t *Token) Seal(cipher cipher.AEAD) (string, error) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	raw, err := t.data.marshal(cipher)
+	if err != nil {
+		return "", err
+	}
+
+	t.modified = false
+
+	return raw, nil
+}
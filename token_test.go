@@ -0,0 +1,194 @@
+package oath
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/hotp"
+	"github.com/dadrus/oath/otp"
+)
+
+func TestToken(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+	alg := newHOTP(t, key, hotp.WithDigits(otp.Digits(8)))
+
+	blob, err := HOTP.New(c, WithKey(key), WithDigits(otp.Digits(8)), WithLockout(3))
+	require.NoError(t, err)
+
+	// WHEN
+	tok, err := Open(blob, c)
+
+	// THEN
+	require.NoError(t, err)
+	assert.False(t, tok.Modified())
+	assert.False(t, tok.Synchronized())
+	assert.Equal(t, Metadata{Type: HOTP, Digits: otp.Digits(8)}, tok.Metadata())
+
+	uri, encodedKey, err := tok.Export("foo", "bar")
+	require.NoError(t, err)
+	assert.Contains(t, uri, "otpauth://hotp/")
+	assert.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", encodedKey)
+	assert.False(t, tok.Modified())
+
+	// WHEN
+	require.ErrorIs(t, tok.Verify("00000000"), otp.ErrValidation)
+	require.NoError(t, tok.Verify(alg.Generate(0)))
+	require.NoError(t, tok.Verify(alg.Generate(1)))
+
+	// THEN
+	assert.True(t, tok.Modified())
+	assert.True(t, tok.Synchronized())
+	assert.Equal(t, int64(2), tok.Metadata().Counter)
+	assert.Zero(t, tok.Metadata().FailedAttempts)
+
+	// WHEN
+	blob, err = tok.Seal(c)
+
+	// THEN
+	require.NoError(t, err)
+	assert.False(t, tok.Modified())
+
+	tok, err = Open(blob, c)
+	require.NoError(t, err)
+	assert.True(t, tok.Synchronized())
+	require.ErrorIs(t, tok.Verify(alg.Generate(1)), otp.ErrReplayed)
+	require.NoError(t, tok.Verify(alg.Generate(2)))
+}
+
+func TestTokenLocked(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+
+	blob, err := HOTP.New(c, WithKey(key), WithLockout(1))
+	require.NoError(t, err)
+
+	blob, _, err = Verify("000000", blob, c)
+	require.ErrorIs(t, err, otp.ErrValidation)
+
+	tok, err := Open(blob, c)
+	require.NoError(t, err)
+
+	// WHEN
+	err = tok.Verify(newHOTP(t, key).Generate(0))
+
+	// THEN
+	require.ErrorIs(t, err, ErrLocked)
+	assert.False(t, tok.Modified())
+	assert.Equal(t, 1, tok.Metadata().FailedAttempts)
+
+	// WHEN
+	tok.Unlock()
+
+	// THEN
+	assert.True(t, tok.Modified())
+	require.NoError(t, tok.Verify(newHOTP(t, key).Generate(0)))
+}
+
+func TestTokenConcurrentVerify(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+
+	blob, err := HOTP.New(c, WithKey(key))
+	require.NoError(t, err)
+
+	tok, err := Open(blob, c)
+	require.NoError(t, err)
+
+	otpValue := newHOTP(t, key).Generate(0)
+
+	var (
+		wg        sync.WaitGroup
+		mut       sync.Mutex
+		successes int
+	)
+
+	// WHEN
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if tok.Verify(otpValue) == nil {
+				mut.Lock()
+				successes++
+				mut.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// THEN
+	assert.Equal(t, 1, successes)
+}
+
+func TestVerifyContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+
+	blob, err := HOTP.New(c, WithKey(key), WithLockout(1))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+
+	<-ctx.Done()
+
+	// WHEN
+	updated, _, err := VerifyContext(ctx, newHOTP(t, key).Generate(0), blob, c)
+
+	// THEN
+	require.ErrorIs(t, err, otp.ErrCanceled)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, blob, updated)
+
+	// the aborted verification did not count as failed attempt
+	_, synced, err := Verify(newHOTP(t, key).Generate(0), updated, c)
+	require.NoError(t, err)
+	assert.True(t, synced)
+}
+
+func TestTokenDestroy(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	c := newAEAD(t)
+	master := []byte("a very secret master secret")
+
+	blob, err := HOTP.New(c, WithKeyDerivation("alice"), WithMasterSecret(master))
+	require.NoError(t, err)
+
+	tok, err := Open(blob, c, WithMasterSecret(master))
+	require.NoError(t, err)
+
+	derived := tok.data.Key
+	secret := tok.data.settings.masterSecret
+
+	// WHEN
+	tok.Destroy()
+
+	// THEN
+	assert.Equal(t, make([]byte, len(derived)), derived)
+	assert.Equal(t, make([]byte, len(secret)), secret)
+	// the master secret provided by the caller is not touched
+	assert.Equal(t, []byte("a very secret master secret"), master)
+}
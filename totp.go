@@ -1,6 +1,8 @@
 package oath
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"golang.org/x/exp/slices"
@@ -16,37 +18,133 @@ type totpBlob struct {
 
 func (b *totpBlob) Synchronized() bool { return b.c.Synchronized }
 
-func (b *totpBlob) OTPURI(account, issuer string) string {
-	return otpauth.ToURI(b.algorithm(), account, otpauth.WithIssuer(issuer))
+func (b *totpBlob) OTPURI(account, issuer string) (string, error) {
+	alg, err := b.algorithm()
+	if err != nil {
+		return "", err
+	}
+
+	defer alg.Destroy()
+
+	if alg.Key() == nil {
+		return "", ErrKeyNotExportable
+	}
+
+	enc := otpauth.NewEncoder(alg, account, otpauth.WithIssuer(issuer))
+	if err = enc.Validate(); err != nil {
+		return "", err
+	}
+
+	return enc.Encode(), nil
 }
 
 func (b *totpBlob) Verify(value string) error {
-	alg := b.algorithm()
-	skew := b.c.Skew()
+	return b.VerifyContext(context.Background(), value)
+}
 
-	deviation, err := alg.Validate(value, time.Now().Unix()+b.c.Deviation, totp.WithSkew(skew))
+func (b *totpBlob) VerifyContext(ctx context.Context, value string) error {
+	alg, err := b.algorithm()
 	if err != nil {
 		return err
 	}
 
-	if !slices.Contains(b.c.LastVerified, value) {
-		b.c.Deviation += deviation
-		b.c.Synchronized = true
+	defer alg.Destroy()
 
-		if len(b.c.LastVerified) >= skew*2+1 {
-			b.c.LastVerified = b.c.LastVerified[1:]
-		}
+	reference := b.reference()
+
+	b.migrate(alg, alg.StepAt(reference))
+
+	res, err := alg.ValidateAtContext(ctx, value, reference, totp.WithWindow(b.c.Window()))
+	if err != nil {
+		return b.classify(alg, value, reference, err)
+	}
 
-		b.c.LastVerified = append(b.c.LastVerified, value)
-	} else {
-		return otp.ErrValidation
+	// as recommended by RFC 6238, section 5.2, an otp value is accepted only once
+	// by accepting only time steps following the last accepted one.
+	if res.Step < b.c.NextStep {
+		return otp.ErrReplayed
 	}
 
+	b.c.Drift = b.drift() + res.Drift
+	b.c.Deviation = 0
+	b.c.Synchronized = true
+	b.c.NextStep = res.Step + 1
+
 	return nil
 }
 
-func (b *totpBlob) algorithm() *totp.Algorithm {
-	return totp.New(b.c.Key,
+// Preview returns the otp value currently shown by the authenticator of the user
+func (b *totpBlob) Preview() (totp.Preview, error) {
+	alg, err := b.algorithm()
+	if err != nil {
+		return totp.Preview{}, err
+	}
+
+	defer alg.Destroy()
+
+	return alg.Preview(b.reference())
+}
+
+// reference returns the current time of the client, which deviates by the tracked drift
+func (b *totpBlob) reference() time.Time {
+	return b.c.settings.now().Add(b.drift())
+}
+
+// drift returns the tracked drift, including the drift in seconds stored by earlier versions
+func (b *totpBlob) drift() time.Duration {
+	return b.c.Drift + time.Duration(b.c.Deviation)*time.Second
+}
+
+// classify checks whether the given value, which failed the validation, belongs to an
+// already consumed time step, or would be valid outside the validity window.
+func (b *totpBlob) classify(alg *totp.Algorithm, value string, reference time.Time, err error) error {
+	if !errors.Is(err, otp.ErrValidation) {
+		return err
+	}
+
+	past, future := b.c.Window()
+
+	res, vErr := alg.ValidateAt(value, reference, totp.WithWindow(past+outOfWindowSkew, future+outOfWindowSkew))
+	if vErr != nil {
+		return err
+	}
+
+	if res.Step < b.c.NextStep {
+		return otp.ErrReplayed
+	}
+
+	return otp.ErrOutOfWindow
+}
+
+// migrate converts the last verified otp values stored by earlier versions to the
+// next acceptable time step. Since the values have been accepted within the validity
+// window, it is sufficient to look for them in the current window.
+func (b *totpBlob) migrate(alg *totp.Algorithm, current int64) {
+	if len(b.c.LastVerified) == 0 {
+		return
+	}
+
+	skew := int64(b.c.WorkSkew)
+	if b.c.InitialSkew > b.c.WorkSkew {
+		skew = int64(b.c.InitialSkew)
+	}
+
+	for step := current - skew; step <= current+skew; step++ {
+		if step >= b.c.NextStep && slices.Contains(b.c.LastVerified, alg.Algorithm.Generate(step)) {
+			b.c.NextStep = step + 1
+		}
+	}
+
+	b.c.LastVerified = nil
+}
+
+func (b *totpBlob) algorithm() (*totp.Algorithm, error) {
+	handle, err := b.c.keyHandle()
+	if err != nil {
+		return nil, err
+	}
+
+	return totp.NewFromHandle(handle,
 		totp.WithHashAlgorithm(b.c.HashAlgorithm),
 		totp.WithDigits(b.c.Digits),
 		totp.WithTimeStep(b.c.Period),
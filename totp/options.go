@@ -21,24 +21,52 @@ func WithHashAlgorithm(algorithm otp.HashAlgorithm) Option {
 	}
 }
 
+// WithKeyPolicy sets the policy the key is checked against. Defaults to otp.DefaultKeyPolicy.
+func WithKeyPolicy(policy otp.KeyPolicy) Option {
+	return func(alg *Algorithm) {
+		hotp.WithKeyPolicy(policy)(&alg.Algorithm)
+	}
+}
+
+// WithTimeStep sets the length of a time step. Sub-second and fractional steps are supported
+// by GenerateAt and ValidateAt. Non-positive values are ignored.
 func WithTimeStep(step time.Duration) Option {
 	return func(alg *Algorithm) {
-		if step != 0 {
+		if step > 0 {
 			alg.step = step
 		}
 	}
 }
 
+// WithT0 sets the unix time in seconds to start counting the time steps from
 func WithT0(t0 int64) Option {
 	return func(alg *Algorithm) {
 		if t0 != 0 {
+			alg.t0 = time.Unix(t0, 0)
+		}
+	}
+}
+
+// WithT0Time sets the instant to start counting the time steps from
+func WithT0Time(t0 time.Time) Option {
+	return func(alg *Algorithm) {
+		if !t0.IsZero() {
 			alg.t0 = t0
 		}
 	}
 }
 
+// WithSkew defines the validity window as skew steps in the past and skew steps
+// in the future.
 func WithSkew(skew int) otp.ValidationOption {
+	return WithWindow(skew, skew)
+}
+
+// WithWindow defines an asymmetric validity window with past steps in the past
+// (look-behind) and future steps in the future (look-ahead). Since values from the
+// future are more suspicious than slightly outdated ones, future is usually smaller.
+func WithWindow(past, future int) otp.ValidationOption {
 	return func(steps int64) otp.SkewIterator {
-		return otp.NewSkewIterator(steps-int64(skew), steps+int64(skew)+1)
+		return otp.NewSkewIterator(steps-int64(past), steps+int64(future)+1)
 	}
 }
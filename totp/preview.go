@@ -0,0 +1,41 @@
+package totp
+
+import "time"
+
+// Preview describes the otp value valid at a given instant, as shown by an authenticator app
+type Preview struct {
+	// Value is the otp value of the current time step
+	Value string
+	// Next is the otp value of the following time step
+	Next string
+	// Step is the current time step
+	Step int64
+	// Start and End are the instants the current time step starts at (inclusive) and ends
+	// at (exclusive)
+	Start time.Time
+	End   time.Time
+	// Remaining is the time the current otp value remains valid
+	Remaining time.Duration
+}
+
+// Preview returns the otp value valid at the given instant, the value of the following time
+// step, as well as the information how long the current value remains valid. Returns
+// otp.ErrMACFailed if the key handle fails to calculate the HMAC.
+func (a *Algorithm) Preview(t time.Time) (Preview, error) {
+	step := a.StepAt(t)
+	end := a.StepStart(step + 1)
+
+	values, err := a.Algorithm.GenerateRange(step, 2) //nolint:gomnd
+	if err != nil {
+		return Preview{}, err
+	}
+
+	return Preview{
+		Value:     values[0],
+		Next:      values[1],
+		Step:      step,
+		Start:     a.StepStart(step),
+		End:       end,
+		Remaining: end.Sub(t),
+	}, nil
+}
@@ -0,0 +1,16 @@
+package totp
+
+import "time"
+
+// GenerateRange generates the otp values of count consecutive time steps starting with
+// the time step the given instant belongs to. See hotp.Algorithm.GenerateRange for details.
+func (a *Algorithm) GenerateRange(from time.Time, count int) ([]string, error) {
+	return a.Algorithm.GenerateRange(a.StepAt(from), count)
+}
+
+// GenerateStream works like GenerateRange, but passes each otp value together with its time
+// step to fn instead of collecting them. See hotp.Algorithm.GenerateStream for details. The
+// start of a time step can be calculated using StepStart.
+func (a *Algorithm) GenerateStream(from time.Time, count int, fn func(step int64, value []byte) bool) error {
+	return a.Algorithm.GenerateStream(a.StepAt(from), count, fn)
+}
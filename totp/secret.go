@@ -0,0 +1,18 @@
+package totp
+
+import (
+	"fmt"
+
+	"github.com/dadrus/oath/otp"
+)
+
+func (a Algorithm) String() string {
+	return fmt.Sprintf("TOTP(%s, %d digits, %s step)", a.HashAlgorithm(), a.Digits(), a.step)
+}
+
+func (a Algorithm) GoString() string {
+	return fmt.Sprintf("totp.Algorithm{key: %s, digits: %d, algorithm: %q, step: %s, t0: %d}",
+		otp.Redacted, a.Digits(), a.HashAlgorithm(), a.step, a.t0.Unix())
+}
+
+func (a Algorithm) Format(f fmt.State, verb rune) { otp.FormatRedacted(f, verb, a) }
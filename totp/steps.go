@@ -0,0 +1,96 @@
+package totp
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+const nanosPerSecond = uint64(time.Second)
+
+// stepsBetween returns floor((t - t0) / step). The difference between t0 and t is
+// calculated with 128 bits to not overflow time.Duration for instants, which are more
+// than ~292 years apart. Results not fitting into int64 are clamped.
+func stepsBetween(t0, t time.Time, step time.Duration) int64 {
+	secs := t.Unix() - t0.Unix()
+	nanos := int64(t.Nanosecond() - t0.Nanosecond())
+
+	if nanos < 0 {
+		secs--
+		nanos += int64(nanosPerSecond)
+	}
+
+	if secs >= 0 {
+		// diff = secs * 1e9 + nanos
+		hi, lo := bits.Mul64(uint64(secs), nanosPerSecond)
+		lo, carry := bits.Add64(lo, uint64(nanos), 0)
+		hi += carry
+
+		if hi >= uint64(step) {
+			return math.MaxInt64
+		}
+
+		quo, _ := bits.Div64(hi, lo, uint64(step))
+		if quo > math.MaxInt64 {
+			return math.MaxInt64
+		}
+
+		return int64(quo)
+	}
+
+	// |diff| = -secs * 1e9 - nanos. Since diff is negative, the quotient is rounded up
+	// and negated to floor it.
+	hi, lo := bits.Mul64(uint64(-secs), nanosPerSecond)
+	lo, borrow := bits.Sub64(lo, uint64(nanos), 0)
+	hi -= borrow
+
+	if hi >= uint64(step) {
+		return math.MinInt64
+	}
+
+	quo, rem := bits.Div64(hi, lo, uint64(step))
+	if rem != 0 {
+		quo++
+	}
+
+	if quo > uint64(math.MaxInt64)+1 {
+		return math.MinInt64
+	}
+
+	return -int64(quo)
+}
+
+// stepStart returns t0 + step * length without overflowing time.Duration. Instants, which
+// cannot be represented as unix time in seconds, are clamped.
+func stepStart(t0 time.Time, step int64, length time.Duration) time.Time {
+	const maxSeconds = uint64(1) << 62
+
+	abs := uint64(step)
+	if step < 0 {
+		abs = -abs
+	}
+
+	hi, lo := bits.Mul64(abs, uint64(length))
+	if hi >= nanosPerSecond {
+		return clampedUnix(step < 0)
+	}
+
+	secs, nanos := bits.Div64(hi, lo, nanosPerSecond)
+	if secs >= maxSeconds {
+		return clampedUnix(step < 0)
+	}
+
+	if step < 0 {
+		return time.Unix(t0.Unix()-int64(secs), int64(t0.Nanosecond())-int64(nanos))
+	}
+
+	return time.Unix(t0.Unix()+int64(secs), int64(t0.Nanosecond())+int64(nanos))
+}
+
+func clampedUnix(negative bool) time.Time {
+	if negative {
+		return time.Unix(math.MinInt64/2, 0) //nolint:gomnd
+	}
+
+	return time.Unix(math.MaxInt64/2, 0) //nolint:gomnd
+}
@@ -0,0 +1,46 @@
+package totp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepsBetween(t *testing.T) {
+	t.Parallel()
+
+	epoch := time.Unix(0, 0)
+
+	for _, tc := range []struct {
+		uc   string
+		t0   time.Time
+		t    time.Time
+		step time.Duration
+		exp  int64
+	}{
+		{uc: "rfc 6238", t0: epoch, t: time.Unix(1111111109, 0), step: 30 * time.Second, exp: 37037036},
+		{uc: "beyond time.Duration", t0: epoch, t: time.Unix(20000000000, 0), step: 30 * time.Second, exp: 666666666},
+		{uc: "sub-second step", t0: epoch, t: time.Unix(10, 600000000), step: 250 * time.Millisecond, exp: 42},
+		{uc: "fractional step", t0: epoch, t: time.Unix(10, 0), step: 1500 * time.Millisecond, exp: 6},
+		{uc: "t0 with nanos", t0: time.Unix(100, 900000000), t: time.Unix(101, 100000000), step: 100 * time.Millisecond, exp: 2},
+		{uc: "before t0", t0: time.Unix(100, 0), t: time.Unix(99, 0), step: 30 * time.Second, exp: -1},
+		{uc: "exactly one step before t0", t0: time.Unix(100, 0), t: time.Unix(70, 0), step: 30 * time.Second, exp: -1},
+		{uc: "clamped", t0: time.Unix(math.MinInt64/2, 0), t: time.Unix(math.MaxInt64/2, 0), step: 1, exp: math.MaxInt64},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			steps := stepsBetween(tc.t0, tc.t, tc.step)
+
+			// THEN
+			assert.Equal(t, tc.exp, steps)
+
+			if steps != math.MaxInt64 {
+				start := stepStart(tc.t0, steps, tc.step)
+				assert.False(t, start.After(tc.t))
+				assert.True(t, stepStart(tc.t0, steps+1, tc.step).After(tc.t))
+			}
+		})
+	}
+}
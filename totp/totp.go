@@ -2,6 +2,7 @@
 package totp
 
 import (
+	"context"
 	"time"
 
 	"github.com/dadrus/oath/hotp"
@@ -12,40 +13,123 @@ type Algorithm struct {
 	hotp.Algorithm
 
 	step time.Duration
-	t0   int64
+	t0   time.Time
 }
 
-func New(key []byte, opts ...Option) *Algorithm {
+// New creates a new TOTP algorithm using the given key held in memory. Returns
+// otp.ErrUnsupportedHashAlgorithm if the configured hash algorithm has not been registered,
+// and otp.ErrWeakKey if the key does not fulfill the key policy (otp.DefaultKeyPolicy,
+// if not configured otherwise).
+func New(key []byte, opts ...Option) (*Algorithm, error) {
+	return newAlgorithm(opts, func(hotpOpts ...hotp.Option) (*hotp.Algorithm, error) {
+		return hotp.New(key, hotpOpts...)
+	})
+}
+
+// NewFromHandle creates a new TOTP algorithm, which lets the given key handle calculate
+// the HMAC, e.g. to keep the key in an HSM. The key policy is not applied. Returns
+// otp.ErrUnsupportedHashAlgorithm if the configured hash algorithm has not been registered.
+func NewFromHandle(handle otp.KeyHandle, opts ...Option) (*Algorithm, error) {
+	return newAlgorithm(opts, func(hotpOpts ...hotp.Option) (*hotp.Algorithm, error) {
+		return hotp.NewFromHandle(handle, hotpOpts...)
+	})
+}
+
+func newAlgorithm(opts []Option, newBase func(opts ...hotp.Option) (*hotp.Algorithm, error)) (*Algorithm, error) {
 	alg := &Algorithm{
-		Algorithm: *hotp.New(key),
-		step:      30 * time.Second, //nolint:gomnd
-		t0:        0,
+		step: 30 * time.Second, //nolint:gomnd
+		t0:   time.Unix(0, 0),
 	}
 
+	hotp.WithKeyPolicy(otp.DefaultKeyPolicy)(&alg.Algorithm)
+
 	for _, opt := range opts {
 		opt(alg)
 	}
 
-	return alg
+	// the options only set the digits, the hash algorithm and the key policy of the embedded algorithm.
+	// The actual one is created afterwards, so that the mac state is created for the
+	// configured hash algorithm.
+	base, err := newBase(
+		hotp.WithDigits(alg.Digits()),
+		hotp.WithHashAlgorithm(alg.HashAlgorithm()),
+		hotp.WithKeyPolicy(alg.KeyPolicy()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	alg.Algorithm = *base
+
+	return alg, nil
 }
 
 func (a *Algorithm) Step() time.Duration { return a.step }
 
-func (a *Algorithm) T0() int64 { return a.t0 }
+// T0 returns the unix time in seconds to start counting the time steps from
+func (a *Algorithm) T0() int64 { return a.t0.Unix() }
+
+// T0Time returns the instant to start counting the time steps from
+func (a *Algorithm) T0Time() time.Time { return a.t0 }
+
+// StepAt returns the time step the given instant belongs to
+func (a *Algorithm) StepAt(t time.Time) int64 { return stepsBetween(a.t0, t, a.step) }
+
+// StepStart returns the instant the given time step starts at
+func (a *Algorithm) StepStart(step int64) time.Time { return stepStart(a.t0, step, a.step) }
 
-func (a *Algorithm) steps(reference int64) int64 { return (reference - a.t0) / int64(a.step.Seconds()) }
+func (a *Algorithm) steps(reference int64) int64 { return a.StepAt(time.Unix(reference, 0)) }
 
 func (a *Algorithm) Generate(reference int64) string {
-	return a.Algorithm.Generate(a.steps(reference))
+	return a.GenerateAt(time.Unix(reference, 0))
 }
 
-func (a *Algorithm) Validate(value string, reference int64, opts ...otp.ValidationOption) (int64, error) {
-	deviation, err := a.Algorithm.Validate(value, a.steps(reference), opts...)
+// GenerateAt generates the otp value for the given instant
+func (a *Algorithm) GenerateAt(t time.Time) string {
+	return a.Algorithm.Generate(a.StepAt(t))
+}
+
+// AppendGenerate works like Generate, but appends the otp value to dst and returns
+// the extended buffer. It does not allocate if dst has enough capacity. Returns
+// otp.ErrMACFailed if the key handle fails to calculate the HMAC.
+func (a *Algorithm) AppendGenerate(dst []byte, reference int64) ([]byte, error) {
+	return a.Algorithm.AppendGenerate(dst, a.steps(reference))
+}
+
+func (a *Algorithm) Validate(value string, reference int64, opts ...otp.ValidationOption) (otp.Result, error) {
+	return a.ValidateAtContext(context.Background(), value, time.Unix(reference, 0), opts...)
+}
+
+// ValidateContext works like Validate, but aborts the validation with otp.ErrCanceled
+// if the given context is done.
+func (a *Algorithm) ValidateContext(
+	ctx context.Context, value string, reference int64, opts ...otp.ValidationOption,
+) (otp.Result, error) {
+	return a.ValidateAtContext(ctx, value, time.Unix(reference, 0), opts...)
+}
+
+// ValidateAt validates the given otp value for the given instant. Other than Validate, it
+// supports time steps and references with sub-second precision.
+func (a *Algorithm) ValidateAt(value string, t time.Time, opts ...otp.ValidationOption) (otp.Result, error) {
+	return a.ValidateAtContext(context.Background(), value, t, opts...)
+}
+
+// ValidateAtContext works like ValidateAt, but aborts the validation with otp.ErrCanceled
+// if the given context is done.
+func (a *Algorithm) ValidateAtContext(
+	ctx context.Context, value string, t time.Time, opts ...otp.ValidationOption,
+) (otp.Result, error) {
+	res, err := a.Algorithm.ValidateContext(ctx, value, a.StepAt(t), opts...)
 	if err != nil {
-		return 0, err
+		return otp.Result{}, err
+	}
+
+	res.Drift = time.Duration(res.Deviation) * a.step
+	if validFor := a.StepStart(res.Step + 1).Sub(t); validFor > 0 {
+		res.ValidFor = validFor
 	}
 
-	return deviation * int64(a.step.Seconds()), nil
+	return res, nil
 }
 
 func (a *Algorithm) Export(exporter otp.Exporter) {
@@ -53,5 +137,5 @@ func (a *Algorithm) Export(exporter otp.Exporter) {
 
 	exporter.SetAlgorithm("totp")
 	exporter.SetPeriod(a.step)
-	exporter.SetT0(a.t0)
+	exporter.SetT0(a.t0.Unix())
 }
@@ -2,6 +2,7 @@ package totp
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -14,6 +15,15 @@ import (
 	"github.com/dadrus/oath/otp/mocks"
 )
 
+func newHOTP(t *testing.T, opts ...hotp.Option) hotp.Algorithm {
+	t.Helper()
+
+	alg, err := hotp.New([]byte("12345678901234567890"), opts...)
+	require.NoError(t, err)
+
+	return *alg
+}
+
 func TestNew(t *testing.T) {
 	t.Parallel()
 
@@ -25,54 +35,55 @@ func TestNew(t *testing.T) {
 		{
 			uc: "defaults",
 			exp: Algorithm{
-				Algorithm: *hotp.New([]byte{}, hotp.WithDigits(otp.Digits(6)), hotp.WithHashAlgorithm(otp.SHA1)),
+				Algorithm: newHOTP(t, hotp.WithDigits(otp.Digits(6)), hotp.WithHashAlgorithm(otp.SHA1)),
 				step:      30 * time.Second,
-				t0:        0,
+				t0:        time.Unix(0, 0),
 			},
 		},
 		{
 			uc:   "digits = 8",
 			opts: []Option{WithDigits(8)},
 			exp: Algorithm{
-				Algorithm: *hotp.New([]byte{}, hotp.WithDigits(otp.Digits(8)), hotp.WithHashAlgorithm(otp.SHA1)),
+				Algorithm: newHOTP(t, hotp.WithDigits(otp.Digits(8)), hotp.WithHashAlgorithm(otp.SHA1)),
 				step:      30 * time.Second,
-				t0:        0,
+				t0:        time.Unix(0, 0),
 			},
 		},
 		{
 			uc:   "hash = sha256",
 			opts: []Option{WithHashAlgorithm(otp.SHA256)},
 			exp: Algorithm{
-				Algorithm: *hotp.New([]byte{}, hotp.WithDigits(otp.Digits(6)), hotp.WithHashAlgorithm(otp.SHA256)),
+				Algorithm: newHOTP(t, hotp.WithDigits(otp.Digits(6)), hotp.WithHashAlgorithm(otp.SHA256)),
 				step:      30 * time.Second,
-				t0:        0,
+				t0:        time.Unix(0, 0),
 			},
 		},
 		{
 			uc:   "step = 45s",
 			opts: []Option{WithTimeStep(45 * time.Second)},
 			exp: Algorithm{
-				Algorithm: *hotp.New([]byte{}, hotp.WithDigits(otp.Digits(6)), hotp.WithHashAlgorithm(otp.SHA1)),
+				Algorithm: newHOTP(t, hotp.WithDigits(otp.Digits(6)), hotp.WithHashAlgorithm(otp.SHA1)),
 				step:      45 * time.Second,
-				t0:        0,
+				t0:        time.Unix(0, 0),
 			},
 		},
 		{
 			uc:   "t0 = 100",
 			opts: []Option{WithT0(100)},
 			exp: Algorithm{
-				Algorithm: *hotp.New([]byte{}, hotp.WithDigits(otp.Digits(6)), hotp.WithHashAlgorithm(otp.SHA1)),
+				Algorithm: newHOTP(t, hotp.WithDigits(otp.Digits(6)), hotp.WithHashAlgorithm(otp.SHA1)),
 				step:      30 * time.Second,
-				t0:        100,
+				t0:        time.Unix(100, 0),
 			},
 		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
 			// GIVENhotp
-			key := []byte{1, 2, 3}
+			key := []byte("12345678901234567890")
 
 			// WHEN
-			alg := New(key, tc.opts...)
+			alg, err := New(key, tc.opts...)
+			require.NoError(t, err)
 
 			// THEN
 			assert.Equal(t, tc.exp.Digits(), alg.Digits())
@@ -129,11 +140,12 @@ func TestGenerate(t *testing.T) {
 	} {
 		t.Run(fmt.Sprintf("otp %s", tc.expOTP), func(t *testing.T) {
 			// GIVEN
-			alg := New(
+			alg, err := New(
 				tc.secret,
 				WithDigits(8),
 				WithHashAlgorithm(tc.hashAlgF),
 			)
+			require.NoError(t, err)
 
 			// WHEN
 			value := alg.Generate(tc.time)
@@ -196,14 +208,15 @@ func TestValidate(t *testing.T) {
 	} {
 		t.Run(fmt.Sprintf("otp %s", tc.otp), func(t *testing.T) {
 			// GIVEN
-			alg := New(
+			alg, err := New(
 				tc.secret,
 				WithDigits(8),
 				WithHashAlgorithm(tc.hashAlgF),
 			)
+			require.NoError(t, err)
 
 			// WHEN
-			_, err := alg.Validate(tc.otp, tc.time)
+			_, err = alg.Validate(tc.otp, tc.time)
 
 			// THEN
 			if tc.success {
@@ -231,6 +244,7 @@ func TestValidateWithSkew(t *testing.T) {
 		skew      int
 		otp       string
 		deviation int64
+		validFor  time.Duration
 		success   bool
 	}{
 		{
@@ -239,6 +253,7 @@ func TestValidateWithSkew(t *testing.T) {
 			skew:      2,
 			otp:       "07081804",
 			deviation: 30,
+			validFor:  31 * time.Second,
 			success:   true,
 		},
 		{
@@ -247,6 +262,7 @@ func TestValidateWithSkew(t *testing.T) {
 			skew:      2,
 			otp:       "07081804",
 			deviation: 60,
+			validFor:  61 * time.Second,
 			success:   true,
 		},
 		{
@@ -262,6 +278,7 @@ func TestValidateWithSkew(t *testing.T) {
 			skew:      2,
 			otp:       "07081804",
 			deviation: 0,
+			validFor:  1 * time.Second,
 			success:   true,
 		},
 		{
@@ -289,13 +306,19 @@ func TestValidateWithSkew(t *testing.T) {
 		},
 	} {
 		t.Run(tc.uc, func(t *testing.T) {
-			alg := New(secret, WithDigits(8))
+			alg, err := New(secret, WithDigits(8))
+			require.NoError(t, err)
 
-			deviation, err := alg.Validate(tc.otp, tc.time, WithSkew(tc.skew))
+			res, err := alg.Validate(tc.otp, tc.time, WithSkew(tc.skew))
 
 			if tc.success {
 				require.NoError(t, err)
-				assert.Equal(t, tc.deviation, deviation)
+				assert.Equal(t, time.Duration(tc.deviation)*time.Second, res.Drift)
+				assert.Equal(t, tc.deviation/30, res.Deviation)
+				assert.Equal(t, int64(1111111109/30), res.Step)
+				assert.Equal(t, tc.validFor, res.ValidFor)
+				assert.Equal(t, res.Step-res.Deviation-2, res.Window.First)
+				assert.Equal(t, res.Step-res.Deviation+2, res.Window.Last)
 			} else {
 				require.Error(t, err)
 				assert.ErrorIs(t, err, otp.ErrValidation)
@@ -304,6 +327,82 @@ func TestValidateWithSkew(t *testing.T) {
 	}
 }
 
+func TestValidateWithWindow(t *testing.T) {
+	t.Parallel()
+
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		uc      string
+		time    int64
+		past    int
+		future  int
+		success bool
+	}{
+		{uc: "value from the past within look-behind", time: 1111111109 + 60, past: 2, future: 0, success: true},
+		{uc: "value from the past beyond look-behind", time: 1111111109 + 90, past: 2, future: 0},
+		{uc: "value from the future without look-ahead", time: 1111111109 - 30, past: 2, future: 0},
+		{uc: "value from the future within look-ahead", time: 1111111109 - 30, past: 0, future: 1, success: true},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// GIVEN
+			alg, err := New(secret, WithDigits(8))
+			require.NoError(t, err)
+
+			// WHEN
+			res, err := alg.Validate("07081804", tc.time, WithWindow(tc.past, tc.future))
+
+			// THEN
+			if tc.success {
+				require.NoError(t, err)
+				assert.Equal(t, int64(1111111109/30), res.Step)
+				assert.Equal(t, alg.steps(tc.time)-int64(tc.past), res.Window.First)
+				assert.Equal(t, alg.steps(tc.time)+int64(tc.future), res.Window.Last)
+			} else {
+				require.ErrorIs(t, err, otp.ErrValidation)
+			}
+		})
+	}
+}
+
+func TestGenerateAndValidateAt(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(t, err)
+
+	alg, err := New(secret, WithDigits(8), WithTimeStep(500*time.Millisecond),
+		WithT0Time(time.Unix(1111111000, 250000000)))
+	require.NoError(t, err)
+
+	now := time.Unix(1111111109, 100000000)
+
+	// WHEN
+	value := alg.GenerateAt(now)
+	res, err := alg.ValidateAt(value, now.Add(600*time.Millisecond), WithWindow(2, 0))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, int64(217), res.Step)
+	assert.Equal(t, int64(-1), res.Deviation)
+	assert.Equal(t, -500*time.Millisecond, res.Drift)
+	assert.Zero(t, res.ValidFor)
+	assert.Equal(t, time.Unix(1111111108, 750000000), alg.StepStart(res.Step))
+
+	// WHEN -> the rfc vectors beyond the range of time.Duration
+	alg, err = New(secret, WithDigits(8))
+	require.NoError(t, err)
+
+	res, err = alg.ValidateAt("65353130", time.Unix(20000000000, 0))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, res.ValidFor)
+	assert.Equal(t, "65353130", alg.Generate(20000000000))
+}
+
 func TestExport(t *testing.T) {
 	t.Parallel()
 
@@ -311,12 +410,13 @@ func TestExport(t *testing.T) {
 	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
 	require.NoError(t, err)
 
-	alg := New(secret,
+	alg, err := New(secret,
 		WithDigits(otp.Digits(8)),
 		WithHashAlgorithm(otp.SHA256),
 		WithT0(10),
 		WithTimeStep(45*time.Second),
 	)
+	require.NoError(t, err)
 
 	exporter := mocks.NewExporterMock(t)
 	exporter.EXPECT().SetAlgorithm("hotp")
@@ -330,3 +430,90 @@ func TestExport(t *testing.T) {
 	// WHEN -> expectations are met
 	alg.Export(exporter)
 }
+
+func TestPreview(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(t, err)
+
+	alg, err := New(secret, WithDigits(8))
+	require.NoError(t, err)
+
+	now := time.Unix(1111111109, 500000000)
+
+	// WHEN
+	preview, err := alg.Preview(now)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "07081804", preview.Value)
+	assert.Equal(t, "14050471", preview.Next)
+	assert.Equal(t, int64(37037036), preview.Step)
+	assert.Equal(t, time.Unix(1111111080, 0), preview.Start)
+	assert.Equal(t, time.Unix(1111111110, 0), preview.End)
+	assert.Equal(t, 500*time.Millisecond, preview.Remaining)
+}
+
+func TestPreviewWithFailingHandle(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	alg, err := NewFromHandle(failingKey{})
+	require.NoError(t, err)
+
+	// WHEN
+	_, pErr := alg.Preview(time.Now())
+	_, gErr := alg.GenerateRange(time.Now(), 2)
+
+	// THEN
+	require.ErrorIs(t, pErr, otp.ErrMACFailed)
+	require.ErrorIs(t, gErr, otp.ErrMACFailed)
+}
+
+// failingKey is a key handle, which is not able to calculate the HMAC, e.g. an unavailable HSM
+type failingKey struct{}
+
+func (failingKey) MAC([]byte, otp.HashAlgorithm, []byte) ([]byte, error) {
+	return nil, errors.New("hsm unavailable")
+}
+
+func TestGenerateRange(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	secret, err := hex.DecodeString("3132333435363738393031323334353637383930")
+	require.NoError(t, err)
+
+	alg, err := New(secret, WithDigits(8))
+	require.NoError(t, err)
+
+	var steps []int64
+
+	// WHEN
+	values, err := alg.GenerateRange(time.Unix(1111111109, 0), 2)
+	require.NoError(t, err)
+
+	err = alg.GenerateStream(time.Unix(1111111109, 0), 2, func(step int64, _ []byte) bool {
+		steps = append(steps, step)
+
+		return true
+	})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []string{"07081804", "14050471"}, values)
+	assert.Equal(t, []int64{37037036, 37037037}, steps)
+}
+
+func TestAlgorithmFormat(t *testing.T) {
+	t.Parallel()
+
+	alg, err := New([]byte("12345678901234567890"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "TOTP(SHA1, 6 digits, 30s step)", fmt.Sprintf("%x", alg))
+	assert.Equal(t, `totp.Algorithm{key: [REDACTED], digits: 6, algorithm: "SHA1", step: 30s, t0: 0}`,
+		fmt.Sprintf("%#v", alg))
+}
@@ -0,0 +1,400 @@
+package oath
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dadrus/oath/oathtest"
+	"github.com/dadrus/oath/otp"
+	"github.com/dadrus/oath/otpauth"
+	"github.com/dadrus/oath/totp"
+)
+
+func TestTOTPBlobRejectsReplayedValues(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	alg := newTOTP(t, key)
+	clock := oathtest.NewClock(time.Unix(1111111109, 0))
+	blb := &totpBlob{c: &config{
+		Key: key, Type: "totp", WorkSkew: 1, InitialSkew: 1,
+		settings: settings{clock: clock},
+	}}
+
+	// WHEN
+	err := blb.Verify(alg.Generate(1111111109))
+
+	// THEN
+	require.NoError(t, err)
+	assert.True(t, blb.Synchronized())
+	assert.Equal(t, int64(1111111109/30+1), blb.c.NextStep)
+
+	// the same value, as well as the values from previous time steps are rejected
+	require.ErrorIs(t, blb.Verify(alg.Generate(1111111109)), otp.ErrValidation)
+	require.ErrorIs(t, blb.Verify(alg.Generate(1111111109-30)), otp.ErrValidation)
+	// but the value from the next time step is accepted
+	require.NoError(t, blb.Verify(alg.Generate(1111111109+30)))
+}
+
+func TestTOTPBlobMigratesLastVerifiedValues(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	alg := newTOTP(t, key)
+	used := alg.Generate(1111111109)
+
+	blb := &totpBlob{c: &config{
+		Key: key, Type: "totp", WorkSkew: 1, InitialSkew: 1, Synchronized: true,
+		LastVerified: []string{"123456", used},
+		settings:     settings{clock: oathtest.NewClock(time.Unix(1111111109, 0))},
+	}}
+
+	// WHEN
+	err := blb.Verify(used)
+
+	// THEN
+	require.ErrorIs(t, err, otp.ErrValidation)
+	assert.Empty(t, blb.c.LastVerified)
+	assert.Equal(t, int64(1111111109/30+1), blb.c.NextStep)
+}
+
+func TestTOTPBlobTracksDrift(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	alg := newTOTP(t, key)
+	clock := oathtest.NewClock(time.Unix(1111111109, 0))
+	blb := &totpBlob{c: &config{
+		Key: key, Type: "totp", WorkSkew: 1, InitialSkew: 2,
+		settings: settings{clock: clock},
+	}}
+
+	// WHEN -> the client is two steps ahead, which is accepted by the initial skew
+	err := blb.Verify(alg.Generate(1111111109 + 60))
+
+	// THEN
+	require.NoError(t, err)
+	assert.True(t, blb.Synchronized())
+	assert.Equal(t, 60*time.Second, blb.c.Drift)
+
+	// WHEN -> time passes, the client is still ahead by two steps, which is compensated
+	// by the tracked deviation, so that the work skew is sufficient
+	clock.Advance(5 * time.Minute)
+	err = blb.Verify(alg.Generate(1111111109 + 5*60 + 60))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 60*time.Second, blb.c.Drift)
+
+	// WHEN -> the client drifts one step further
+	clock.Advance(5 * time.Minute)
+	err = blb.Verify(alg.Generate(1111111109 + 10*60 + 90))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, blb.c.Drift)
+
+	// WHEN -> without the tracked deviation the value would be out of the work skew
+	clock.Advance(5 * time.Minute)
+	blb.c.Drift = 0
+	err = blb.Verify(alg.Generate(1111111109 + 15*60 + 90))
+
+	// THEN
+	require.ErrorIs(t, err, otp.ErrValidation)
+}
+
+func TestTOTPBlobTracksSubSecondDrift(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	alg := newTOTP(t, key, totp.WithTimeStep(250*time.Millisecond))
+	now := time.Unix(1111111109, 0)
+	clock := oathtest.NewClock(now)
+	blb := &totpBlob{c: &config{
+		Key: key, Type: "totp", Period: 250 * time.Millisecond, WorkSkew: 1, InitialSkew: 2,
+		settings: settings{clock: clock},
+	}}
+
+	// WHEN -> the client is two steps ahead
+	err := blb.Verify(alg.GenerateAt(now.Add(500 * time.Millisecond)))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, blb.c.Drift)
+
+	// WHEN -> the client drifts one step further
+	clock.Advance(time.Minute)
+	err = blb.Verify(alg.GenerateAt(now.Add(time.Minute + 750*time.Millisecond)))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 750*time.Millisecond, blb.c.Drift)
+}
+
+func TestTOTPBlobMigratesDeviationInSeconds(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	alg := newTOTP(t, key)
+	blb := &totpBlob{c: &config{
+		Key: key, Type: "totp", WorkSkew: 1, InitialSkew: 1, Synchronized: true, Deviation: 60,
+		settings: settings{clock: oathtest.NewClock(time.Unix(1111111109, 0))},
+	}}
+
+	// WHEN -> the value is accepted only if the deviation stored by earlier versions is
+	// taken into account
+	err := blb.Verify(alg.Generate(1111111109 + 90))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, blb.c.Drift)
+	assert.Zero(t, blb.c.Deviation)
+}
+
+func TestTOTPBlobClassifiesFailures(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("12345678901234567890")
+	alg := newTOTP(t, key)
+
+	for _, tc := range []struct {
+		uc    string
+		value string
+		err   error
+	}{
+		{uc: "valid value", value: alg.Generate(1111111109 + 30)},
+		{uc: "wrong value", value: "000000", err: otp.ErrValidation},
+		{uc: "already used value", value: alg.Generate(1111111109), err: otp.ErrReplayed},
+		{uc: "already used value outside of the window", value: alg.Generate(1111111109 - 5*30), err: otp.ErrReplayed},
+		{uc: "value ahead of the window", value: alg.Generate(1111111109 + 5*30), err: otp.ErrOutOfWindow},
+		{uc: "too short value", value: "12345", err: otp.ErrInvalidLength},
+		{uc: "not numeric value", value: "12345a", err: otp.ErrMalformed},
+	} {
+		tc := tc
+
+		t.Run(tc.uc, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN -> the value of the current time step has already been used
+			blb := &totpBlob{c: &config{
+				Key: key, Type: "totp", WorkSkew: 1, InitialSkew: 1, Synchronized: true,
+				NextStep: 1111111109/30 + 1,
+				settings: settings{clock: oathtest.NewClock(time.Unix(1111111109, 0))},
+			}}
+
+			// WHEN
+			err := blb.Verify(tc.value)
+
+			// THEN
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTOTPBlobDetectsValuesOutsideOfTheWindow(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	alg := newTOTP(t, key)
+	blb := &totpBlob{c: &config{
+		Key: key, Type: "totp", WorkSkew: 1, InitialSkew: 1,
+		settings: settings{clock: oathtest.NewClock(time.Unix(1111111109, 0))},
+	}}
+
+	// WHEN
+	err := blb.Verify(alg.Generate(1111111109 - 5*30))
+
+	// THEN
+	require.ErrorIs(t, err, otp.ErrOutOfWindow)
+	require.ErrorIs(t, err, otp.ErrValidation)
+	assert.False(t, blb.Synchronized())
+}
+
+func TestTOTPBlobAsymmetricWindow(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	alg := newTOTP(t, key)
+	noFuture := 0
+	clock := oathtest.NewClock(time.Unix(1111111109, 0))
+	blb := &totpBlob{c: &config{
+		Key: key, Type: "totp", WorkSkew: 2, InitialSkew: 2, Synchronized: true,
+		WorkFutureSkew: &noFuture,
+		settings:       settings{clock: clock},
+	}}
+
+	// WHEN -> the value from the next time step is not accepted
+	err := blb.Verify(alg.Generate(1111111109 + 30))
+
+	// THEN
+	require.ErrorIs(t, err, otp.ErrOutOfWindow)
+
+	// WHEN -> but a value two steps in the past is
+	err = blb.Verify(alg.Generate(1111111109 - 60))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, -60*time.Second, blb.c.Drift)
+
+	// WHEN -> the client is still two steps behind, which is compensated by the tracked
+	// deviation, so that the current value of the client is accepted
+	clock.Advance(5 * time.Minute)
+	err = blb.Verify(alg.Generate(1111111109 + 5*60 - 60))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, -60*time.Second, blb.c.Drift)
+}
+
+func TestNewWithWindows(t *testing.T) {
+	t.Parallel()
+
+	c := newAEAD(t)
+
+	for _, tc := range []struct {
+		uc                          string
+		opts                        []Option
+		expWork, expInitial         [2]int
+		expInitialFutureSkewPresent bool
+	}{
+		{
+			uc:         "symmetric",
+			opts:       []Option{WithWorkSkew(1), WithInitialSkew(2)},
+			expWork:    [2]int{1, 1},
+			expInitial: [2]int{2, 2},
+		},
+		{
+			uc:         "asymmetric",
+			opts:       []Option{WithWorkWindow(2, 0), WithInitialWindow(4, 1)},
+			expWork:    [2]int{2, 0},
+			expInitial: [2]int{4, 1},
+
+			expInitialFutureSkewPresent: true,
+		},
+		{
+			uc:         "work window only",
+			opts:       []Option{WithWorkWindow(2, 0)},
+			expWork:    [2]int{2, 0},
+			expInitial: [2]int{2, 0},
+
+			expInitialFutureSkewPresent: true,
+		},
+		{
+			uc:         "work window with symmetric initial skew",
+			opts:       []Option{WithWorkWindow(2, 0), WithInitialSkew(4)},
+			expWork:    [2]int{2, 0},
+			expInitial: [2]int{4, 0},
+
+			expInitialFutureSkewPresent: true,
+		},
+		{
+			uc:         "initial window smaller than work window",
+			opts:       []Option{WithWorkWindow(2, 3), WithInitialSkew(1)},
+			expWork:    [2]int{2, 3},
+			expInitial: [2]int{2, 3},
+
+			expInitialFutureSkewPresent: true,
+		},
+	} {
+		t.Run(tc.uc, func(t *testing.T) {
+			// WHEN
+			blob, err := TOTP.New(c, tc.opts...)
+			require.NoError(t, err)
+
+			// THEN
+			var cfg config
+
+			require.NoError(t, cfg.unmarshal(blob, c))
+			assert.Equal(t, tc.expInitialFutureSkewPresent, cfg.InitialFutureSkew != nil)
+
+			past, future := cfg.Window()
+			assert.Equal(t, tc.expInitial, [2]int{past, future})
+
+			cfg.Synchronized = true
+			past, future = cfg.Window()
+			assert.Equal(t, tc.expWork, [2]int{past, future})
+		})
+	}
+}
+
+func TestTOTPBlobWithSubSecondTimeStep(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	alg := newTOTP(t, key, totp.WithTimeStep(500*time.Millisecond))
+	clock := oathtest.NewClock(time.Unix(1111111109, 300000000))
+	blb := &totpBlob{c: &config{
+		Key: key, Type: "totp", Period: 500 * time.Millisecond,
+		settings: settings{clock: clock},
+	}}
+
+	// WHEN
+	err := blb.Verify(alg.GenerateAt(clock.Now()))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, int64(2222222218+1), blb.c.NextStep)
+
+	// WHEN -> the value of the next step
+	clock.Advance(500 * time.Millisecond)
+	err = blb.Verify(alg.GenerateAt(clock.Now()))
+
+	// THEN
+	require.NoError(t, err)
+
+	// WHEN -> the period cannot be expressed in an otpauth uri
+	_, err = blb.OTPURI("foo", "bar")
+
+	// THEN
+	require.ErrorIs(t, err, otpauth.ErrUnsupportedPeriod)
+}
+
+func TestPreview(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	key := []byte("12345678901234567890")
+	c := newAEAD(t)
+	clock := oathtest.NewClock(time.Unix(1111111109, 0))
+
+	blob, err := TOTP.New(c, WithKey(key), WithInitialSkew(2))
+	require.NoError(t, err)
+
+	// the clock of the client is 60 seconds ahead
+	blob, _, err = Verify(newTOTP(t, key).Generate(1111111109+60), blob, c, WithClock(clock))
+	require.NoError(t, err)
+
+	// WHEN
+	preview, err := Preview(blob, c, WithClock(clock))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, newTOTP(t, key).Generate(1111111109+60), preview.Value)
+	assert.Equal(t, newTOTP(t, key).Generate(1111111109+90), preview.Next)
+	assert.Equal(t, time.Unix(1111111140, 0), preview.Start)
+	assert.Equal(t, 1*time.Second, preview.Remaining)
+
+	// WHEN
+	blob, err = HOTP.New(c)
+	require.NoError(t, err)
+
+	_, err = Preview(blob, c)
+
+	// THEN
+	require.ErrorIs(t, err, ErrPreviewNotSupported)
+}
@@ -1,8 +1,12 @@
 package oath
 
 import (
+	"context"
 	"crypto/cipher"
 	"errors"
+	"fmt"
+
+	"github.com/dadrus/oath/otp"
 )
 
 var ErrInvalidOTPType = errors.New("invalid otp type")
@@ -11,30 +15,55 @@ var ErrInvalidOTPType = errors.New("invalid otp type")
 // algorithm configuration (blobValue) by making use of the provided cipher.
 // This function returns the updated sealed blob (first return value), as well as the information
 // whether the synchronization with the client application has taken place (second return value).
-func Verify(otpValue string, blobValue string, cipher cipher.AEAD) (string, bool, error) {
-	data, blb, err := blob(blobValue, cipher)
+// If the cipher is a Keyring, the updated blob is always sealed with its current key.
+// Only options not affecting the blob configuration itself, like WithSubject, are taken
+// into account. The exception are WithBackoff and WithLockout, which override the respective
+// policy stored in the blob, e.g. to introduce throttling for existing blobs. Use Open to
+// perform several operations on a blob.
+//
+// The updated blob is also returned if the verification fails, as it tracks the failed
+// attempts, which are used to lock it, if configured (see WithBackoff and WithLockout).
+// If the blob is locked, a LockedError is returned.
+func Verify(otpValue string, blobValue string, cipher cipher.AEAD, opts ...Option) (string, bool, error) {
+	return VerifyContext(context.Background(), otpValue, blobValue, cipher, opts...)
+}
+
+// VerifyContext works like Verify, but aborts the verification with otp.ErrCanceled if the
+// given context is done. Only wrong otp values count as failed attempts. Aborted
+// verifications and failures of the key provider leave the blob unmodified.
+func VerifyContext(
+	ctx context.Context, otpValue string, blobValue string, cipher cipher.AEAD, opts ...Option,
+) (string, bool, error) {
+	tok, err := Open(blobValue, cipher, opts...)
 	if err != nil {
 		return "", false, err
 	}
 
-	err = blb.Verify(otpValue)
-	if err != nil {
-		return "", data.Synchronized, err
+	defer tok.Destroy()
+
+	err = tok.VerifyContext(ctx, otpValue)
+	if !tok.Modified() {
+		return blobValue, tok.Synchronized(), err
 	}
 
-	raw, err := data.marshal(cipher)
+	raw, mErr := tok.Seal(cipher)
+	if mErr != nil {
+		return "", tok.Synchronized(), mErr
+	}
 
-	return raw, data.Synchronized, err
+	return raw, tok.Synchronized(), err
 }
 
-func blob(blobValue string, cipher cipher.AEAD) (*config, Blob, error) {
-	var data config
+func blob(blobValue string, cipher cipher.AEAD, opts []Option) (*config, Blob, error) {
+	data := config{settings: settingsFrom(opts)}
 
 	err := data.unmarshal(blobValue, cipher)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	data.mergeLockout()
+
 	var blb Blob
 
 	switch data.Type {
@@ -46,5 +75,13 @@ func blob(blobValue string, cipher cipher.AEAD) (*config, Blob, error) {
 		return nil, nil, ErrInvalidOTPType
 	}
 
+	if len(data.HashAlgorithm) != 0 && !data.HashAlgorithm.Supported() {
+		return nil, nil, fmt.Errorf("%w: %s", otp.ErrUnsupportedHashAlgorithm, data.HashAlgorithm)
+	}
+
+	if err = data.deriveKey(); err != nil {
+		return nil, nil, err
+	}
+
 	return &data, blb, nil
 }